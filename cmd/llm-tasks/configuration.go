@@ -7,17 +7,32 @@ import (
 )
 
 func loadRootConfiguration(configurationPath string) (config.Root, error) {
+	rootConfiguration, _, err := loadRootConfigurationWithSource(configurationPath)
+	return rootConfiguration, err
+}
+
+// loadRootConfigurationWithSource also returns the resolved source, for commands that edit it.
+func loadRootConfigurationWithSource(configurationPath string) (config.Root, config.RootConfigurationSource, error) {
+	configurationSource, sourceErr := resolveConfigurationSource(configurationPath)
+	if sourceErr != nil {
+		return config.Root{}, config.RootConfigurationSource{}, sourceErr
+	}
+	rootConfiguration, loadErr := config.LoadRoot(configurationSource)
+	if loadErr != nil {
+		return config.Root{}, configurationSource, fmt.Errorf(rootConfigurationLoadErrorFormat, configurationSource.Reference, loadErr)
+	}
+	return rootConfiguration, configurationSource, nil
+}
+
+// resolveConfigurationSource finds the configuration file through the default search paths.
+func resolveConfigurationSource(configurationPath string) (config.RootConfigurationSource, error) {
 	configurationLoader, loaderErr := config.NewDefaultRootConfigurationLoader()
 	if loaderErr != nil {
-		return config.Root{}, fmt.Errorf(configurationLoaderInitializationErrorFormat, loaderErr)
+		return config.RootConfigurationSource{}, fmt.Errorf(configurationLoaderInitializationErrorFormat, loaderErr)
 	}
 	configurationSource, sourceErr := configurationLoader.Load(configurationPath)
 	if sourceErr != nil {
-		return config.Root{}, fmt.Errorf(configurationSourceResolutionErrorFormat, sourceErr)
-	}
-	rootConfiguration, loadErr := config.LoadRoot(configurationSource)
-	if loadErr != nil {
-		return config.Root{}, fmt.Errorf(rootConfigurationLoadErrorFormat, configurationSource.Reference, loadErr)
+		return config.RootConfigurationSource{}, fmt.Errorf(configurationSourceResolutionErrorFormat, sourceErr)
 	}
-	return rootConfiguration, nil
+	return configurationSource, nil
 }
@@ -23,17 +23,55 @@ const (
 	changelogVersionFlagUsage                    = "Changelog version metadata (exported to CHANGELOG_VERSION)"
 	changelogDateFlagName                        = "date"
 	changelogDateFlagUsage                       = "Changelog date metadata (exported to CHANGELOG_DATE)"
+	recordFlagName                               = "record"
+	recordFlagUsage                              = "Record every LLM request/response pair into this cassette directory"
+	replayFlagName                               = "replay"
+	replayFlagUsage                              = "Serve LLM responses from this cassette directory (no network, no API key)"
+	dryRunFlagName                               = "dry-run"
+	dryRunFlagUsage                              = "Show what the task would change without applying it"
+	undoCommandUse                               = "undo <run-id>"
+	undoCommandShort                             = "Move files from a sort run back to where they came from"
+	undoRecipeFlagUsage                          = "Sort recipe whose staging directory holds the run journal"
+	sortCommandUse                               = "sort"
+	sortCommandShort                             = "Maintain the sort recipe"
+	sortApproveCommandUse                        = "approve [PROJECT...]"
+	sortApproveCommandShort                      = "Add model-proposed projects to the sort recipe in config.yaml"
+	sortApproveAllFlagName                       = "all"
+	sortApproveAllFlagUsage                      = "Approve every pending proposal"
+	sortRecipeFlagUsage                          = "Sort recipe whose proposals to review"
+	watchCommandUse                              = "watch"
+	watchCommandShort                            = "Run a task continuously as new files arrive"
+	watchSortCommandUse                          = "sort"
+	watchSortCommandShort                        = "Sort new downloads in time-windowed batches once their size settles"
+	watchRecipeFlagUsage                         = "Sort recipe whose downloads directory to watch"
+	watchIntervalFlagName                        = "interval"
+	watchIntervalFlagUsage                       = "How often to scan the downloads directory"
+	watchSettleFlagName                          = "settle"
+	watchSettleFlagUsage                         = "How long a file's size must stay unchanged before it is sorted"
+	watchWindowFlagName                          = "window"
+	watchWindowFlagUsage                         = "How long to collect arrivals after the first one before sorting them as one batch"
+	watchBackoffFlagName                         = "backoff"
+	watchBackoffFlagUsage                        = "Wait before retrying files from a failed batch; doubles per failure, up to 1h"
+	watchMaxRetriesFlagName                      = "max-retries"
+	watchMaxRetriesFlagUsage                     = "Failed batches a file may take part in before it is left alone until it changes"
+	watchStateDirName                            = ".llm-tasks-watch"
+	watchStateFileName                           = "state.json"
+	validateCommandUse                           = "validate"
+	validateCommandShort                         = "Check config.yaml for schema, model and environment problems without running a task"
+	validateJSONFlagName                         = "json"
+	validateJSONFlagUsage                        = "Print the findings as JSON (for CI)"
+	validationSeverityError                      = "error"
+	validationSeverityWarning                    = "warning"
 	listCommandUse                               = "list"
 	listCommandShort                             = "List recipes from config.yaml (enabled by default)"
 	enabledStateLabel                            = "enabled"
 	disabledStateLabel                           = "disabled"
 	dashPlaceholder                              = "-"
-	defaultAPIEndpoint                           = "https://api.openai.com/v1"
-	defaultAPIKeyEnvironmentVariable             = "OPENAI_API_KEY"
 	configurationLoaderInitializationErrorFormat = "initialize configuration loader: %w"
 	configurationSourceResolutionErrorFormat     = "resolve configuration source: %w"
 	rootConfigurationLoadErrorFormat             = "load root configuration from %s: %w"
 	changelogRecipeType                          = "task/changelog"
 	sortRecipeType                               = "task/sort"
+	lowConfidencePolicyAsk                       = "ask"
 	setEnvironmentVariableErrorFormat            = "set environment variable %s: %w"
 )
@@ -16,6 +16,10 @@ func NewRootCommand() *cobra.Command {
 
 	rootCommand.AddCommand(newListCommand())
 	rootCommand.AddCommand(newRunCommand())
+	rootCommand.AddCommand(newUndoCommand())
+	rootCommand.AddCommand(newSortCommand())
+	rootCommand.AddCommand(newWatchCommand())
+	rootCommand.AddCommand(newValidateCommand())
 
 	return rootCommand
 }
@@ -14,6 +14,9 @@ type runCommandOptions struct {
 	modelOverride    string
 	changelogVersion string
 	changelogDate    string
+	recordDir        string
+	replayDir        string
+	dryRun           bool
 }
 
 func newRunCommand() *cobra.Command {
@@ -42,6 +45,10 @@ func newRunCommand() *cobra.Command {
 	command.Flags().StringVar(&options.configPath, configFlagName, defaultConfigPath, configFlagUsage)
 	command.Flags().StringVar(&options.changelogVersion, changelogVersionFlagName, "", changelogVersionFlagUsage)
 	command.Flags().StringVar(&options.changelogDate, changelogDateFlagName, "", changelogDateFlagUsage)
+	command.Flags().StringVar(&options.recordDir, recordFlagName, "", recordFlagUsage)
+	command.Flags().StringVar(&options.replayDir, replayFlagName, "", replayFlagUsage)
+	command.Flags().BoolVar(&options.dryRun, dryRunFlagName, false, dryRunFlagUsage)
+	command.MarkFlagsMutuallyExclusive(recordFlagName, replayFlagName)
 
 	return command
 }
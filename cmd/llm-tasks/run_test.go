@@ -250,3 +250,77 @@ func buildRunArguments(configPath, versionFlag, dateFlag string) []string {
 	}
 	return arguments
 }
+
+func TestRunCommandRecordThenReplay(testingT *testing.T) {
+	serverCalls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		serverCalls++
+		responseText := "## [v1.0.0] - 2025-01-01\n\n### Highlights\n\n- Item\n\n### Features ✨\n\n- Feature\n\n### Improvements ⚙️\n\n- Improvement\n"
+		responseWriter.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(responseWriter, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, responseText)
+	}))
+
+	temporaryDirectory := testingT.TempDir()
+	configPath := filepath.Join(temporaryDirectory, "config.yaml")
+	changelogPath := filepath.Join(temporaryDirectory, "CHANGELOG.md")
+	cassetteDir := filepath.Join(temporaryDirectory, "cassette")
+	configContent := fmt.Sprintf(changelogConfigTemplate, mockServer.URL, changelogPath)
+	if writeErr := os.WriteFile(configPath, []byte(configContent), 0o600); writeErr != nil {
+		testingT.Fatalf("write config: %v", writeErr)
+	}
+	testingT.Setenv(changelogVersionEnvName, "")
+	testingT.Setenv(changelogDateEnvName, "")
+
+	runOnce := func(apiKey string, extraArguments ...string) string {
+		testingT.Setenv(openAIAPIKeyEnvName, apiKey)
+		seedStdin(testingT, changelogGitLogSample)
+		command := llmtasks.NewRootCommand()
+		arguments := append(buildRunArguments(configPath, "v1.0.0", "2025-01-01"), extraArguments...)
+		command.SetArgs(arguments)
+		var outputBuffer bytes.Buffer
+		command.SetOut(&outputBuffer)
+		command.SetErr(&outputBuffer)
+		if executeErr := command.Execute(); executeErr != nil {
+			testingT.Fatalf("execute run command %v: %v\noutput:%s", arguments, executeErr, outputBuffer.String())
+		}
+		changelogData, readErr := os.ReadFile(changelogPath)
+		if readErr != nil {
+			testingT.Fatalf("read changelog output: %v", readErr)
+		}
+		if removeErr := os.Remove(changelogPath); removeErr != nil {
+			testingT.Fatalf("reset changelog: %v", removeErr)
+		}
+		return string(changelogData)
+	}
+
+	recorded := runOnce(openAIAPIKeyValue, "--record", cassetteDir)
+	mockServer.Close()
+	replayed := runOnce("", "--replay", cassetteDir)
+
+	if serverCalls != 1 {
+		testingT.Fatalf("expected exactly one live call, got %d", serverCalls)
+	}
+	if recorded != replayed {
+		testingT.Fatalf("replayed output differs from recorded output\nrecorded:\n%s\nreplayed:\n%s", recorded, replayed)
+	}
+}
+
+func seedStdin(testingT *testing.T, content string) {
+	testingT.Helper()
+	stdinReader, stdinWriter, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		testingT.Fatalf("create stdin pipe: %v", pipeErr)
+	}
+	if _, writeErr := stdinWriter.WriteString(content); writeErr != nil {
+		testingT.Fatalf("seed stdin: %v", writeErr)
+	}
+	if closeErr := stdinWriter.Close(); closeErr != nil {
+		testingT.Fatalf("close stdin writer: %v", closeErr)
+	}
+	originalStdin := os.Stdin
+	os.Stdin = stdinReader
+	testingT.Cleanup(func() {
+		_ = stdinReader.Close()
+		os.Stdin = originalStdin
+	})
+}
@@ -0,0 +1,117 @@
+package llmtasks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	sorttask "github.com/temirov/llm-tasks/tasks/sort"
+)
+
+type sortApproveCommandOptions struct {
+	configPath string
+	recipeName string
+	all        bool
+}
+
+func newSortCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   sortCommandUse,
+		Short: sortCommandShort,
+	}
+	command.AddCommand(newSortApproveCommand())
+	return command
+}
+
+func newSortApproveCommand() *cobra.Command {
+	options := &sortApproveCommandOptions{configPath: defaultConfigPath, recipeName: defaultTaskName}
+
+	command := &cobra.Command{
+		Use:   sortApproveCommandUse,
+		Short: sortApproveCommandShort,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSortApproveCommand(cmd, *options, args)
+		},
+	}
+
+	command.Flags().StringVar(&options.configPath, configFlagName, defaultConfigPath, configFlagUsage)
+	command.Flags().StringVar(&options.recipeName, taskNameFlagName, defaultTaskName, sortRecipeFlagUsage)
+	command.Flags().BoolVar(&options.all, sortApproveAllFlagName, false, sortApproveAllFlagUsage)
+
+	return command
+}
+
+// runSortApproveCommand lists pending proposals when none are named, otherwise appends the
+// approved ones to the recipe's projects list and drops them from the review file.
+func runSortApproveCommand(command *cobra.Command, options sortApproveCommandOptions, names []string) error {
+	rootConfiguration, configurationSource, err := loadRootConfigurationWithSource(options.configPath)
+	if err != nil {
+		return err
+	}
+	recipe, recipeFound := rootConfiguration.FindRecipe(options.recipeName)
+	if !recipeFound || recipe.Type != sortRecipeType {
+		return fmt.Errorf("unknown sort recipe %q", options.recipeName)
+	}
+	sortConfiguration, loadErr := sorttask.NewUnifiedProvider(rootConfiguration, recipe.Name).Load()
+	if loadErr != nil {
+		return fmt.Errorf("load sort recipe %s: %w", recipe.Name, loadErr)
+	}
+	fs := sorttask.DefaultFS()
+	staging := sortConfiguration.Grant.BaseDirectories.Staging
+	pending, pendingErr := sorttask.LoadProposals(fs, staging)
+	if pendingErr != nil {
+		return fmt.Errorf("load project proposals: %w", pendingErr)
+	}
+
+	outputWriter := command.OutOrStdout()
+	if len(names) == 0 && !options.all {
+		if len(pending) == 0 {
+			_, writeErr := fmt.Fprintln(outputWriter, "no pending project proposals")
+			return writeErr
+		}
+		for _, proposal := range pending {
+			if _, writeErr := fmt.Fprintf(outputWriter, "%s (target=%s, files=%d, keywords=%s)\n", proposal.Name, proposal.Target, proposal.FileCount, strings.Join(proposal.Keywords, ",")); writeErr != nil {
+				return fmt.Errorf("write project proposal: %w", writeErr)
+			}
+		}
+		_, writeErr := fmt.Fprintf(outputWriter, "review %s, then approve by name or with --%s\n", sorttask.ProposalsPath(fs, staging), sortApproveAllFlagName)
+		return writeErr
+	}
+	if configurationSource.Reference == config.EmbeddedRootConfigurationReference {
+		return fmt.Errorf("no config.yaml found to update; pass --%s", configFlagName)
+	}
+
+	approved, remaining, selectErr := sorttask.SelectProposals(pending, names, options.all)
+	if selectErr != nil {
+		return selectErr
+	}
+	projects := make([]config.SortProject, 0, len(approved))
+	for _, proposal := range approved {
+		projects = append(projects, config.SortProject{Name: proposal.Name, Target: proposal.Target, Keywords: proposal.Keywords})
+	}
+	updated, appendErr := config.AppendSortProjects(configurationSource.Content, recipe.Name, projects)
+	if appendErr != nil {
+		return fmt.Errorf("update %s: %w", configurationSource.Reference, appendErr)
+	}
+	info, statErr := os.Stat(configurationSource.Reference)
+	if statErr != nil {
+		return fmt.Errorf("update %s: %w", configurationSource.Reference, statErr)
+	}
+	if writeErr := os.WriteFile(configurationSource.Reference, updated, info.Mode().Perm()); writeErr != nil {
+		return fmt.Errorf("update %s: %w", configurationSource.Reference, writeErr)
+	}
+	if saveErr := sorttask.SaveProposals(fs, staging, remaining); saveErr != nil {
+		return fmt.Errorf("save project proposals: %w", saveErr)
+	}
+
+	for _, project := range projects {
+		if _, writeErr := fmt.Fprintf(outputWriter, "approved %s -> %s\n", project.Name, project.Target); writeErr != nil {
+			return fmt.Errorf("write approval: %w", writeErr)
+		}
+	}
+	_, writeErr := fmt.Fprintf(outputWriter, "added %d project(s) to %s, %d proposal(s) still pending\n", len(projects), configurationSource.Reference, len(remaining))
+	return writeErr
+}
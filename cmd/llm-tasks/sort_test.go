@@ -0,0 +1,111 @@
+package llmtasks_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	llmtasks "github.com/temirov/llm-tasks/cmd/llm-tasks"
+)
+
+const (
+	sortApproveConfigTemplate = `models:
+  - name: stub
+    model_id: stub-model
+    default: true
+
+recipes:
+  - name: sort
+    enabled: true
+    type: task/sort
+    grant:
+      base_directories:
+        downloads: %[1]s
+        staging: %[1]s/_sorted
+    projects:
+      # curated by hand
+      - name: "Taxes"
+        target: "Taxes"
+        keywords: ["w2"]   # forms
+    thresholds:
+      min_confidence: 0.6
+`
+	sortProposalsFixture = `proposals:
+  - name: Board Games
+    target: Board Games
+    keywords: [rulebook, meeple]
+    file_count: 2
+  - name: Recipes
+    target: Recipes
+    keywords: [soup]
+    file_count: 1
+`
+)
+
+func TestSortApproveCommandAppendsProjects(testingT *testing.T) {
+	tempDirectory := testingT.TempDir()
+	configPath := filepath.Join(tempDirectory, "config.yaml")
+	if writeErr := os.WriteFile(configPath, []byte(fmt.Sprintf(sortApproveConfigTemplate, tempDirectory)), 0o600); writeErr != nil {
+		testingT.Fatalf("write config: %v", writeErr)
+	}
+	proposalsPath := filepath.Join(tempDirectory, "_sorted", ".llm-tasks-proposals.yaml")
+	if mkdirErr := os.MkdirAll(filepath.Dir(proposalsPath), 0o755); mkdirErr != nil {
+		testingT.Fatalf("mkdir staging: %v", mkdirErr)
+	}
+	if writeErr := os.WriteFile(proposalsPath, []byte(sortProposalsFixture), 0o644); writeErr != nil {
+		testingT.Fatalf("write proposals: %v", writeErr)
+	}
+
+	var listing bytes.Buffer
+	listCommand := llmtasks.NewRootCommand()
+	listCommand.SetOut(&listing)
+	listCommand.SetArgs([]string{"sort", "approve", "--config", configPath})
+	if executeErr := listCommand.Execute(); executeErr != nil {
+		testingT.Fatalf("list proposals: %v", executeErr)
+	}
+	if !strings.Contains(listing.String(), "Board Games (target=Board Games, files=2, keywords=rulebook,meeple)") {
+		testingT.Fatalf("expected pending proposals to be listed, got %q", listing.String())
+	}
+
+	var output bytes.Buffer
+	approveCommand := llmtasks.NewRootCommand()
+	approveCommand.SetOut(&output)
+	approveCommand.SetArgs([]string{"sort", "approve", "--config", configPath, "board games"})
+	if executeErr := approveCommand.Execute(); executeErr != nil {
+		testingT.Fatalf("approve: %v", executeErr)
+	}
+
+	updated, readErr := os.ReadFile(configPath)
+	if readErr != nil {
+		testingT.Fatalf("read config: %v", readErr)
+	}
+	expectedProjects := `    projects:
+      # curated by hand
+      - name: "Taxes"
+        target: "Taxes"
+        keywords: ["w2"]   # forms
+      - name: "Board Games"
+        target: "Board Games"
+        keywords: ["rulebook","meeple"]
+    thresholds:`
+	if !strings.Contains(string(updated), expectedProjects) {
+		testingT.Fatalf("unexpected config after approve:\n%s", updated)
+	}
+	if info, statErr := os.Stat(configPath); statErr != nil || info.Mode().Perm() != 0o600 {
+		testingT.Fatalf("expected config permissions to be kept, got %v (%v)", info.Mode().Perm(), statErr)
+	}
+
+	remaining, readErr := os.ReadFile(proposalsPath)
+	if readErr != nil {
+		testingT.Fatalf("read proposals: %v", readErr)
+	}
+	if strings.Contains(string(remaining), "Board Games") || !strings.Contains(string(remaining), "Recipes") {
+		testingT.Fatalf("expected only Recipes to stay pending, got:\n%s", remaining)
+	}
+	if !strings.Contains(output.String(), "approved Board Games -> Board Games") {
+		testingT.Fatalf("unexpected approve output %q", output.String())
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 
 	"github.com/temirov/llm-tasks/internal/config"
 	"github.com/temirov/llm-tasks/internal/llm"
+	"github.com/temirov/llm-tasks/internal/logging"
 	"github.com/temirov/llm-tasks/internal/pipeline"
 	changelogtask "github.com/temirov/llm-tasks/tasks/changelog"
 	sorttask "github.com/temirov/llm-tasks/tasks/sort"
@@ -17,6 +19,8 @@ import (
 
 type pipelineBuilder func(root config.Root, recipe config.Recipe) (pipeline.Pipeline, error)
 
+var llmProviders = llm.DefaultProviders()
+
 var pipelineBuilders = map[string]pipelineBuilder{
 	sortRecipeType:      buildSortPipeline,
 	changelogRecipeType: buildChangelogPipeline,
@@ -56,39 +60,42 @@ func runTaskCommand(command *cobra.Command, options runCommandOptions) error {
 		}
 	}
 
-	selectedModelName := resolveModelName(options, targetRecipe, rootConfiguration)
-	modelConfiguration, modelFound := rootConfiguration.FindModel(selectedModelName)
-	if !modelFound {
-		return fmt.Errorf("model %q not found in models[]", selectedModelName)
+	runner, modelConfiguration, runnerErr := buildRunner(command, options, rootConfiguration, targetRecipe)
+	if runnerErr != nil {
+		return runnerErr
 	}
+	defer func() { _ = runner.Logger.Sync() }()
 
-	apiKeyEnvironmentVariable := strings.TrimSpace(rootConfiguration.Common.API.APIKeyEnv)
-	if apiKeyEnvironmentVariable == "" {
-		apiKeyEnvironmentVariable = defaultAPIKeyEnvironmentVariable
+	taskPipeline, builderErr := buildPipeline(rootConfiguration, targetRecipe, mappedChangelogConfig)
+	if builderErr != nil {
+		return builderErr
 	}
-	apiKey := strings.TrimSpace(os.Getenv(apiKeyEnvironmentVariable))
-	if apiKey == "" {
-		return fmt.Errorf("missing API key: set %s", apiKeyEnvironmentVariable)
+
+	executionContext := command.Context()
+	report, runErr := runner.Run(executionContext, taskPipeline)
+	if runErr != nil {
+		return fmt.Errorf("run pipeline %s: %w", targetRecipe.Name, runErr)
 	}
 
-	apiEndpoint := strings.TrimSpace(rootConfiguration.Common.API.Endpoint)
-	if apiEndpoint == "" {
-		apiEndpoint = defaultAPIEndpoint
+	return writeRunReport(command, report, modelConfiguration.Pricing)
+}
+
+// buildRunner resolves the model, logger and LLM client for a recipe and applies the run flags.
+func buildRunner(command *cobra.Command, options runCommandOptions, rootConfiguration config.Root, targetRecipe config.Recipe) (pipeline.Runner, config.Model, error) {
+	selectedModelName := resolveModelName(options, targetRecipe, rootConfiguration)
+	modelConfiguration, modelFound := rootConfiguration.FindModel(selectedModelName)
+	if !modelFound {
+		return pipeline.Runner{}, config.Model{}, fmt.Errorf("model %q not found in models[]", selectedModelName)
 	}
 
-	httpClient := llm.Client{
-		HTTPBaseURL:       apiEndpoint,
-		APIKey:            apiKey,
-		ModelIdentifier:   modelConfiguration.ModelID,
-		MaxTokensResponse: modelConfiguration.MaxCompletionTokens,
-		Temperature:       modelConfiguration.DefaultTemperature,
+	logger, loggerErr := logging.New(rootConfiguration.Common.Logging.Level, rootConfiguration.Common.Logging.Format, command.ErrOrStderr())
+	if loggerErr != nil {
+		return pipeline.Runner{}, config.Model{}, fmt.Errorf("configure logging: %w", loggerErr)
 	}
-	adapter := llm.Adapter{
-		Client:              httpClient,
-		DefaultModel:        modelConfiguration.ModelID,
-		DefaultTemp:         modelConfiguration.DefaultTemperature,
-		DefaultTokens:       modelConfiguration.MaxCompletionTokens,
-		SupportsTemperature: modelConfiguration.SupportsTemperature,
+
+	client, clientErr := buildClient(options, rootConfiguration, modelConfiguration, logger)
+	if clientErr != nil {
+		return pipeline.Runner{}, config.Model{}, clientErr
 	}
 
 	effectiveAttempts := rootConfiguration.Common.Defaults.Attempts
@@ -107,32 +114,81 @@ func runTaskCommand(command *cobra.Command, options runCommandOptions) error {
 		effectiveTimeout = 45 * time.Second
 	}
 
-	runner := pipeline.Runner{
-		Client: adapter,
+	return pipeline.Runner{
+		Client: client,
+		Logger: logger,
 		Options: pipeline.RunOptions{
 			MaxAttempts: effectiveAttempts,
-			DryRun:      false,
+			DryRun:      options.dryRun,
 			Timeout:     effectiveTimeout,
 		},
-	}
+	}, modelConfiguration, nil
+}
 
-	taskPipeline, builderErr := buildPipeline(rootConfiguration, targetRecipe, mappedChangelogConfig)
-	if builderErr != nil {
-		return builderErr
+func writeRunReport(command *cobra.Command, report pipeline.ApplyReport, pricing config.Pricing) error {
+	summary := report.Summary
+	if report.FailedBatches > 0 {
+		summary = fmt.Sprintf("%s [%d batch(es) failed, see log]", summary, report.FailedBatches)
+	}
+	_, writeErr := fmt.Fprintf(command.OutOrStdout(), "%s (actions=%d, dry=%v, %s)\n", summary, report.NumActions, report.DryRun, formatUsage(report.Usage, pricing))
+	if writeErr != nil {
+		return fmt.Errorf("write run result: %w", writeErr)
 	}
+	return nil
+}
 
-	executionContext := command.Context()
-	report, runErr := runner.Run(executionContext, taskPipeline)
-	if runErr != nil {
-		return fmt.Errorf("run pipeline %s: %w", targetRecipe.Name, runErr)
+// buildClient wires the LLM client for a run, honoring --replay (offline) and --record (pass-through capture).
+func buildClient(options runCommandOptions, root config.Root, model config.Model, logger *zap.Logger) (pipeline.LLMClient, error) {
+	cassetteModel := llm.CassetteModel{Provider: model.Provider, ModelID: model.ModelID}
+	replayDir := strings.TrimSpace(options.replayDir)
+	if replayDir != "" {
+		return llm.ReplayClient{Dir: replayDir, Model: cassetteModel}, nil
 	}
+	adapter, adapterErr := buildAdapter(root, model, logger)
+	if adapterErr != nil {
+		return nil, adapterErr
+	}
+	recordDir := strings.TrimSpace(options.recordDir)
+	if recordDir != "" {
+		return llm.RecordingClient{Next: adapter, Dir: recordDir, Model: cassetteModel}, nil
+	}
+	return adapter, nil
+}
 
-	_, writeErr := fmt.Fprintf(command.OutOrStdout(), "%s (actions=%d, dry=%v)\n", report.Summary, report.NumActions, report.DryRun)
-	if writeErr != nil {
-		return fmt.Errorf("write run result: %w", writeErr)
+func buildAdapter(root config.Root, model config.Model, logger *zap.Logger) (llm.Adapter, error) {
+	provider, providerFound := llmProviders.Lookup(model.Provider)
+	if !providerFound {
+		return llm.Adapter{}, fmt.Errorf("model %q: unknown provider %q (known: %s)", model.Name, model.Provider, strings.Join(llmProviders.Names(), ", "))
+	}
+	endpoint, endpointErr := provider.Resolve(endpointSettings(root, model), os.LookupEnv)
+	if endpointErr != nil {
+		return llm.Adapter{}, fmt.Errorf("model %q: %w", model.Name, endpointErr)
+	}
+	return llm.Adapter{
+		Client:              provider.NewClient(endpoint),
+		DefaultModel:        model.ModelID,
+		DefaultTemp:         model.DefaultTemperature,
+		DefaultTokens:       model.MaxCompletionTokens,
+		SupportsTemperature: model.SupportsTemperature,
+		Logger:              logger.With(zap.String("model", model.Name), zap.String("provider", model.Provider)),
+	}, nil
+}
+
+func endpointSettings(root config.Root, model config.Model) llm.EndpointSettings {
+	return llm.EndpointSettings{
+		ModelEndpoint:   model.Endpoint,
+		ModelAPIKeyEnv:  model.APIKeyEnv,
+		CommonEndpoint:  root.Common.API.Endpoint,
+		CommonAPIKeyEnv: root.Common.API.APIKeyEnv,
 	}
+}
 
-	return nil
+func formatUsage(usage pipeline.Usage, pricing config.Pricing) string {
+	formatted := fmt.Sprintf("tokens=%d prompt=%d completion=%d", usage.TotalTokens, usage.PromptTokens, usage.CompletionTokens)
+	if pricing == (config.Pricing{}) {
+		return formatted
+	}
+	return fmt.Sprintf("%s cost=$%.4f", formatted, pricing.Cost(usage.PromptTokens, usage.CompletionTokens))
 }
 
 func resolveModelName(options runCommandOptions, recipe config.Recipe, root config.Root) string {
@@ -0,0 +1,34 @@
+package llmtasks
+
+import (
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+func TestFormatUsage(t *testing.T) {
+	usage := pipeline.Usage{PromptTokens: 2000, CompletionTokens: 500, TotalTokens: 2500}
+	testCases := []struct {
+		name     string
+		pricing  config.Pricing
+		expected string
+	}{
+		{
+			name:     "WithoutPricing",
+			expected: "tokens=2500 prompt=2000 completion=500",
+		},
+		{
+			name:     "WithPricing",
+			pricing:  config.Pricing{PromptPer1K: 0.25, CompletionPer1K: 2},
+			expected: "tokens=2500 prompt=2000 completion=500 cost=$1.5000",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := formatUsage(usage, testCase.pricing); got != testCase.expected {
+				t.Fatalf("expected %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
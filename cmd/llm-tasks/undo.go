@@ -0,0 +1,66 @@
+package llmtasks
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	sorttask "github.com/temirov/llm-tasks/tasks/sort"
+)
+
+type undoCommandOptions struct {
+	configPath string
+	recipeName string
+}
+
+func newUndoCommand() *cobra.Command {
+	options := &undoCommandOptions{configPath: defaultConfigPath, recipeName: defaultTaskName}
+
+	command := &cobra.Command{
+		Use:   undoCommandUse,
+		Short: undoCommandShort,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUndoCommand(cmd, *options, args[0])
+		},
+	}
+
+	command.Flags().StringVar(&options.configPath, configFlagName, defaultConfigPath, configFlagUsage)
+	command.Flags().StringVar(&options.recipeName, taskNameFlagName, defaultTaskName, undoRecipeFlagUsage)
+
+	return command
+}
+
+func runUndoCommand(command *cobra.Command, options undoCommandOptions, runID string) error {
+	rootConfiguration, err := loadRootConfiguration(options.configPath)
+	if err != nil {
+		return err
+	}
+	recipe, recipeFound := rootConfiguration.FindRecipe(options.recipeName)
+	if !recipeFound || recipe.Type != sortRecipeType {
+		return fmt.Errorf("unknown sort recipe %q", options.recipeName)
+	}
+	sortConfiguration, loadErr := sorttask.NewUnifiedProvider(rootConfiguration, recipe.Name).Load()
+	if loadErr != nil {
+		return fmt.Errorf("load sort recipe %s: %w", recipe.Name, loadErr)
+	}
+
+	report, undoErr := sorttask.Undo(command.Context(), sorttask.DefaultFS(), sortConfiguration.Grant.BaseDirectories.Staging, runID)
+	if undoErr != nil {
+		return fmt.Errorf("undo run %s: %w", runID, undoErr)
+	}
+
+	outputWriter := command.OutOrStdout()
+	for _, conflict := range report.Conflicts {
+		if _, writeErr := fmt.Fprintf(outputWriter, "conflict: %s -> %s: %s\n", conflict.Entry.To, conflict.Entry.From, conflict.Reason); writeErr != nil {
+			return fmt.Errorf("write undo conflict: %w", writeErr)
+		}
+	}
+	if _, writeErr := fmt.Fprintf(outputWriter, "undo %s: restored %d file(s), %d conflict(s)\n", runID, report.Restored, len(report.Conflicts)); writeErr != nil {
+		return fmt.Errorf("write undo result: %w", writeErr)
+	}
+	if len(report.Conflicts) > 0 {
+		return fmt.Errorf("undo run %s left %d conflict(s); resolve them and re-run undo", runID, len(report.Conflicts))
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package llmtasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	sorttask "github.com/temirov/llm-tasks/tasks/sort"
+)
+
+type validateCommandOptions struct {
+	configPath string
+	jsonOutput bool
+}
+
+// validationFinding is one problem in the configuration. Errors make the configuration
+// unusable; warnings are environment variables a run would need but that are unset here.
+type validationFinding struct {
+	Severity string `json:"severity"`
+	Recipe   string `json:"recipe,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Message  string `json:"message"`
+}
+
+type validationReport struct {
+	Config   string              `json:"config"`
+	Valid    bool                `json:"valid"`
+	Findings []validationFinding `json:"findings"`
+}
+
+func newValidateCommand() *cobra.Command {
+	options := &validateCommandOptions{configPath: defaultConfigPath}
+
+	command := &cobra.Command{
+		Use:   validateCommandUse,
+		Short: validateCommandShort,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidateCommand(cmd, *options)
+		},
+	}
+
+	command.Flags().StringVar(&options.configPath, configFlagName, defaultConfigPath, configFlagUsage)
+	command.Flags().BoolVar(&options.jsonOutput, validateJSONFlagName, false, validateJSONFlagUsage)
+
+	return command
+}
+
+// runValidateCommand prints every finding and fails when any of them is an error.
+func runValidateCommand(command *cobra.Command, options validateCommandOptions) error {
+	configurationSource, sourceErr := resolveConfigurationSource(options.configPath)
+	if sourceErr != nil {
+		return sourceErr
+	}
+
+	findings := validateConfiguration(configurationSource, os.LookupEnv)
+	report := validationReport{Config: configurationSource.Reference, Valid: true, Findings: findings}
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == validationSeverityError {
+			errorCount++
+			report.Valid = false
+		}
+	}
+
+	writer := command.OutOrStdout()
+	var writeErr error
+	if options.jsonOutput {
+		writeErr = writeValidationJSON(writer, report)
+	} else {
+		writeErr = writeValidationText(writer, report, errorCount)
+	}
+	if writeErr != nil {
+		return fmt.Errorf("write validation report: %w", writeErr)
+	}
+
+	if errorCount > 0 {
+		// the findings already explain the failure; usage would only bury them
+		command.SilenceUsage = true
+		return fmt.Errorf("configuration %s is invalid: %d error(s)", configurationSource.Reference, errorCount)
+	}
+	return nil
+}
+
+// validateConfiguration checks the recipe schemas, model references, the default model,
+// recipe types and, for enabled recipes, the environment variables a run would read.
+func validateConfiguration(source config.RootConfigurationSource, lookup func(string) (string, bool)) []validationFinding {
+	rootConfiguration, decodeErr := config.DecodeRoot(source)
+	if decodeErr != nil {
+		return []validationFinding{{Severity: validationSeverityError, Message: decodeErr.Error()}}
+	}
+
+	findings := []validationFinding{}
+	if modelsErr := rootConfiguration.CheckModels(); modelsErr != nil {
+		findings = append(findings, validationFinding{Severity: validationSeverityError, Message: modelsErr.Error()})
+	}
+	var defaultModels []string
+	for _, model := range rootConfiguration.Models {
+		if model.Default {
+			defaultModels = append(defaultModels, model.Name)
+		}
+		if _, providerFound := llmProviders.Lookup(model.Provider); !providerFound {
+			findings = append(findings, validationFinding{
+				Severity: validationSeverityError,
+				Model:    model.Name,
+				Message:  fmt.Sprintf("unknown provider %q (known: %s)", model.Provider, strings.Join(llmProviders.Names(), ", ")),
+			})
+		}
+	}
+	if len(defaultModels) > 1 {
+		findings = append(findings, validationFinding{
+			Severity: validationSeverityError,
+			Message:  fmt.Sprintf("exactly one model must be default, found %d: %s", len(defaultModels), strings.Join(defaultModels, ", ")),
+		})
+	}
+
+	for _, recipe := range rootConfiguration.Recipes {
+		findings = append(findings, validateRecipe(rootConfiguration, recipe, lookup)...)
+	}
+	return findings
+}
+
+func validateRecipe(rootConfiguration config.Root, recipe config.Recipe, lookup func(string) (string, bool)) []validationFinding {
+	var findings []validationFinding
+	recipeError := func(format string, arguments ...any) {
+		findings = append(findings, validationFinding{Severity: validationSeverityError, Recipe: recipe.Name, Message: fmt.Sprintf(format, arguments...)})
+	}
+	recipeWarning := func(format string, arguments ...any) {
+		findings = append(findings, validationFinding{Severity: validationSeverityWarning, Recipe: recipe.Name, Message: fmt.Sprintf(format, arguments...)})
+	}
+
+	if _, builderFound := pipelineBuilders[recipe.Type]; !builderFound {
+		recipeError("unknown recipe type %q (known: %s)", recipe.Type, strings.Join(slices.Sorted(maps.Keys(pipelineBuilders)), ", "))
+	}
+	modelName := resolveModelName(runCommandOptions{}, recipe, rootConfiguration)
+	model, modelFound := rootConfiguration.FindModel(modelName)
+	if !modelFound && modelName != "" {
+		recipeError("model %q not found in models[]", modelName)
+	}
+	if !recipe.Enabled {
+		return findings
+	}
+
+	if provider, providerFound := llmProviders.Lookup(model.Provider); modelFound && providerFound {
+		settings := endpointSettings(rootConfiguration, model)
+		if _, endpointErr := provider.Resolve(settings, anyAPIKey); endpointErr != nil {
+			recipeError("model %q: %v", model.Name, endpointErr)
+		}
+		keyEnv := provider.APIKeyEnv(settings)
+		if value, _ := lookup(keyEnv); provider.RequiresAPIKey && strings.TrimSpace(value) == "" {
+			recipeWarning("model %q: API key environment variable %s is not set", model.Name, keyEnv)
+		}
+	}
+	if recipe.Type == sortRecipeType {
+		if _, grantErr := sorttask.NewUnifiedProvider(rootConfiguration, recipe.Name).Load(); grantErr != nil {
+			recipeWarning("%v", grantErr)
+		}
+	}
+	return findings
+}
+
+// anyAPIKey lets Provider.Resolve check the endpoint alone; the key is reported separately.
+func anyAPIKey(string) (string, bool) { return "set", true }
+
+func writeValidationText(writer io.Writer, report validationReport, errorCount int) error {
+	for _, finding := range report.Findings {
+		subject := ""
+		switch {
+		case finding.Recipe != "":
+			subject = fmt.Sprintf("recipe %q: ", finding.Recipe)
+		case finding.Model != "":
+			subject = fmt.Sprintf("model %q: ", finding.Model)
+		}
+		message := strings.ReplaceAll(finding.Message, "\n", "\n  ")
+		if _, err := fmt.Fprintf(writer, "%s: %s%s\n", finding.Severity, subject, message); err != nil {
+			return err
+		}
+	}
+	status := "ok"
+	if len(report.Findings) > 0 {
+		status = fmt.Sprintf("%d error(s), %d warning(s)", errorCount, len(report.Findings)-errorCount)
+	}
+	_, err := fmt.Fprintf(writer, "%s: %s\n", report.Config, status)
+	return err
+}
+
+func writeValidationJSON(writer io.Writer, report validationReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "%s\n", data)
+	return err
+}
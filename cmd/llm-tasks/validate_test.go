@@ -0,0 +1,160 @@
+package llmtasks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	llmtasks "github.com/temirov/llm-tasks/cmd/llm-tasks"
+)
+
+const validateConfigTemplate = `models:
+  - name: primary
+    provider: openai
+    model_id: primary-model
+    api_key_env: VALIDATE_TEST_API_KEY
+    default: %[3]s
+%[1]s
+recipes:
+  - name: sort
+    enabled: true
+    type: task/sort
+    model: %[2]s
+    grant:
+      base_directories:
+        downloads: ${VALIDATE_TEST_DOWNLOADS}
+        staging: /staging
+%[4]s`
+
+const validateNotesRecipe = "  - name: notes\n    enabled: false\n    type: task/notes\n"
+
+func TestValidateCommand(testingT *testing.T) {
+	testCases := []struct {
+		name               string
+		extraModels        string
+		extraRecipes       string
+		noDefaultModel     bool
+		recipeModel        string
+		environment        map[string]string
+		arguments          []string
+		expectError        bool
+		expectedSubstrings []string
+	}{
+		{
+			name:         "ReportsEveryProblem",
+			extraModels:  "  - name: secondary\n    provider: openai\n    model_id: secondary-model\n    default: true\n",
+			extraRecipes: validateNotesRecipe,
+			recipeModel:  "missing",
+			expectError:  true,
+			expectedSubstrings: []string{
+				"error: exactly one model must be default, found 2: primary, secondary",
+				`error: recipe "sort": model "missing" not found in models[]`,
+				`error: recipe "notes": unknown recipe type "task/notes" (known: task/changelog, task/sort)`,
+				"3 error(s), 1 warning(s)",
+			},
+		},
+		{
+			name:           "ReportsMissingDefaultModelWithOtherProblems",
+			extraRecipes:   validateNotesRecipe,
+			noDefaultModel: true,
+			recipeModel:    "missing",
+			expectError:    true,
+			expectedSubstrings: []string{
+				"error: no default model found (set models[].default: true)",
+				`error: recipe "sort": model "missing" not found in models[]`,
+				`error: recipe "notes": unknown recipe type "task/notes"`,
+				"3 error(s), 1 warning(s)",
+			},
+		},
+		{
+			name:        "WarnsAboutUnsetEnvironment",
+			recipeModel: "primary",
+			expectedSubstrings: []string{
+				`warning: recipe "sort": model "primary": API key environment variable VALIDATE_TEST_API_KEY is not set`,
+				`warning: recipe "sort": resolve grant.base_directories.downloads: missing environment variable(s): VALIDATE_TEST_DOWNLOADS`,
+			},
+		},
+		{
+			name:         "JSON",
+			extraRecipes: validateNotesRecipe,
+			recipeModel:  "primary",
+			environment:  map[string]string{"VALIDATE_TEST_API_KEY": "key", "VALIDATE_TEST_DOWNLOADS": "/downloads"},
+			arguments:    []string{"--json"},
+			expectError:  true,
+			expectedSubstrings: []string{
+				`"valid": false`,
+				`"recipe": "notes"`,
+			},
+		},
+		{
+			name:               "Clean",
+			recipeModel:        "primary",
+			environment:        map[string]string{"VALIDATE_TEST_API_KEY": "key", "VALIDATE_TEST_DOWNLOADS": "/downloads"},
+			expectedSubstrings: []string{"config.yaml: ok\n"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testingT.Run(testCase.name, func(t *testing.T) {
+			for name, value := range testCase.environment {
+				t.Setenv(name, value)
+			}
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			defaultModel := strconv.FormatBool(!testCase.noDefaultModel)
+			content := strings.NewReplacer("%[1]s", testCase.extraModels, "%[2]s", testCase.recipeModel, "%[3]s", defaultModel, "%[4]s", testCase.extraRecipes).Replace(validateConfigTemplate)
+			if writeErr := os.WriteFile(configPath, []byte(content), 0o644); writeErr != nil {
+				t.Fatalf("write config: %v", writeErr)
+			}
+
+			var output bytes.Buffer
+			command := llmtasks.NewRootCommand()
+			command.SetOut(&output)
+			command.SetErr(&bytes.Buffer{})
+			command.SetArgs(append([]string{"validate", "--config", configPath}, testCase.arguments...))
+			executeErr := command.Execute()
+			if (executeErr != nil) != testCase.expectError {
+				t.Fatalf("unexpected error state: %v\n%s", executeErr, output.String())
+			}
+			for _, expected := range testCase.expectedSubstrings {
+				if !strings.Contains(output.String(), expected) {
+					t.Fatalf("expected %q in output:\n%s", expected, output.String())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCommandReportsSchemaErrorsAsJSON(testingT *testing.T) {
+	configPath := filepath.Join(testingT.TempDir(), "config.yaml")
+	content := "models:\n  - name: primary\n    default: true\nrecipes:\n  - name: sort\n    type: task/sort\n    thresholds:\n      min_confidance: 0.9\n"
+	if writeErr := os.WriteFile(configPath, []byte(content), 0o644); writeErr != nil {
+		testingT.Fatalf("write config: %v", writeErr)
+	}
+
+	var output bytes.Buffer
+	command := llmtasks.NewRootCommand()
+	command.SetOut(&output)
+	command.SetErr(&bytes.Buffer{})
+	command.SetArgs([]string{"validate", "--config", configPath, "--json"})
+	if executeErr := command.Execute(); executeErr == nil {
+		testingT.Fatalf("expected validation to fail")
+	}
+
+	var report struct {
+		Valid    bool `json:"valid"`
+		Findings []struct {
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+		} `json:"findings"`
+	}
+	if decodeErr := json.Unmarshal(output.Bytes(), &report); decodeErr != nil {
+		testingT.Fatalf("decode report: %v\n%s", decodeErr, output.String())
+	}
+	if report.Valid || len(report.Findings) != 1 || !strings.Contains(report.Findings[0].Message, `line 8, column 7: unknown field "thresholds.min_confidance"`) {
+		testingT.Fatalf("unexpected report: %+v", report)
+	}
+}
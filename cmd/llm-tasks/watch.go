@@ -0,0 +1,124 @@
+package llmtasks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/temirov/llm-tasks/internal/watch"
+	sorttask "github.com/temirov/llm-tasks/tasks/sort"
+)
+
+type watchSortCommandOptions struct {
+	run        runCommandOptions
+	interval   time.Duration
+	settle     time.Duration
+	window     time.Duration
+	backoff    time.Duration
+	maxRetries int
+}
+
+func newWatchCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   watchCommandUse,
+		Short: watchCommandShort,
+	}
+	command.AddCommand(newWatchSortCommand())
+	return command
+}
+
+func newWatchSortCommand() *cobra.Command {
+	options := &watchSortCommandOptions{
+		run:        runCommandOptions{configPath: defaultConfigPath, taskName: defaultTaskName},
+		interval:   watch.DefaultInterval,
+		settle:     watch.DefaultSettle,
+		window:     watch.DefaultWindow,
+		backoff:    watch.DefaultBackoff,
+		maxRetries: watch.DefaultMaxRetries,
+	}
+
+	command := &cobra.Command{
+		Use:   watchSortCommandUse,
+		Short: watchSortCommandShort,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatchSortCommand(cmd, *options)
+		},
+	}
+
+	command.Flags().StringVar(&options.run.configPath, configFlagName, defaultConfigPath, configFlagUsage)
+	command.Flags().StringVar(&options.run.taskName, taskNameFlagName, defaultTaskName, watchRecipeFlagUsage)
+	command.Flags().IntVar(&options.run.attempts, attemptsFlagName, 0, attemptsFlagUsage)
+	command.Flags().DurationVar(&options.run.timeout, timeoutFlagName, 0, timeoutFlagUsage)
+	command.Flags().StringVar(&options.run.modelOverride, modelFlagName, "", modelFlagUsage)
+	command.Flags().BoolVar(&options.run.dryRun, dryRunFlagName, false, dryRunFlagUsage)
+	command.Flags().DurationVar(&options.interval, watchIntervalFlagName, watch.DefaultInterval, watchIntervalFlagUsage)
+	command.Flags().DurationVar(&options.settle, watchSettleFlagName, watch.DefaultSettle, watchSettleFlagUsage)
+	command.Flags().DurationVar(&options.window, watchWindowFlagName, watch.DefaultWindow, watchWindowFlagUsage)
+	command.Flags().DurationVar(&options.backoff, watchBackoffFlagName, watch.DefaultBackoff, watchBackoffFlagUsage)
+	command.Flags().IntVar(&options.maxRetries, watchMaxRetriesFlagName, watch.DefaultMaxRetries, watchMaxRetriesFlagUsage)
+
+	return command
+}
+
+// runWatchSortCommand watches the recipe's downloads directory until interrupted and runs the
+// sort pipeline on each settled batch of new files. The state file in the staging directory
+// keeps files that stay in place (skipped, dry run) from being classified again.
+func runWatchSortCommand(command *cobra.Command, options watchSortCommandOptions) error {
+	rootConfiguration, err := loadRootConfiguration(options.run.configPath)
+	if err != nil {
+		return err
+	}
+	recipe, recipeFound := rootConfiguration.FindRecipe(options.run.taskName)
+	if !recipeFound || !recipe.Enabled || recipe.Type != sortRecipeType {
+		return fmt.Errorf("unknown or disabled sort recipe %q", options.run.taskName)
+	}
+	provider := sorttask.NewUnifiedProvider(rootConfiguration, recipe.Name)
+	sortConfiguration, loadErr := provider.Load()
+	if loadErr != nil {
+		return fmt.Errorf("load sort recipe %s: %w", recipe.Name, loadErr)
+	}
+	if strings.TrimSpace(sortConfiguration.LowConfidencePolicy) == lowConfidencePolicyAsk {
+		return fmt.Errorf("sort recipe %s: low_confidence_policy %q needs a terminal; use refine, route_to_inbox or skip with watch", recipe.Name, lowConfidencePolicyAsk)
+	}
+
+	runner, modelConfiguration, runnerErr := buildRunner(command, options.run, rootConfiguration, recipe)
+	if runnerErr != nil {
+		return runnerErr
+	}
+	defer func() { _ = runner.Logger.Sync() }()
+
+	fs := sorttask.DefaultFS()
+	watcher := watch.Watcher{
+		FS:        fs,
+		Root:      sortConfiguration.Grant.BaseDirectories.Downloads,
+		StatePath: fs.FS.Join(sortConfiguration.Grant.BaseDirectories.Staging, watchStateDirName, watchStateFileName),
+		Options: watch.Options{
+			Interval:   options.interval,
+			Settle:     options.settle,
+			Window:     options.window,
+			Backoff:    options.backoff,
+			MaxRetries: options.maxRetries,
+		},
+		Logger: runner.Logger.With(zap.String("recipe", recipe.Name)),
+	}
+
+	executionContext, stop := signal.NotifyContext(command.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watcher.Run(executionContext, func(ctx context.Context, paths []string) ([]string, error) {
+		task := sorttask.NewForFiles(fs, provider, paths)
+		report, runErr := runner.Run(ctx, task)
+		if runErr != nil {
+			return nil, fmt.Errorf("run pipeline %s: %w", recipe.Name, runErr)
+		}
+		// a run with failed batches still succeeds; their files go back to the watcher for a retry
+		return task.Unsorted(), writeRunReport(command, report, modelConfiguration.Pricing)
+	})
+}
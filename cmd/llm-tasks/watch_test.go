@@ -0,0 +1,45 @@
+package llmtasks_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	llmtasks "github.com/temirov/llm-tasks/cmd/llm-tasks"
+)
+
+const watchAskConfigTemplate = `models:
+  - name: stub
+    model_id: stub-model
+    default: true
+
+recipes:
+  - name: sort
+    enabled: true
+    type: task/sort
+    grant:
+      base_directories:
+        downloads: %[1]s
+        staging: %[1]s/_sorted
+    low_confidence_policy: ask
+`
+
+func TestWatchSortRejectsAskPolicy(testingT *testing.T) {
+	tempDirectory := testingT.TempDir()
+	configPath := filepath.Join(tempDirectory, "config.yaml")
+	if writeErr := os.WriteFile(configPath, []byte(fmt.Sprintf(watchAskConfigTemplate, tempDirectory)), 0o600); writeErr != nil {
+		testingT.Fatalf("write config: %v", writeErr)
+	}
+
+	command := llmtasks.NewRootCommand()
+	command.SetOut(&bytes.Buffer{})
+	command.SetErr(&bytes.Buffer{})
+	command.SetArgs([]string{"watch", "sort", "--config", configPath})
+	executeErr := command.Execute()
+	if executeErr == nil || !strings.Contains(executeErr.Error(), `low_confidence_policy "ask" needs a terminal`) {
+		testingT.Fatalf("expected watch to reject the ask policy, got %v", executeErr)
+	}
+}
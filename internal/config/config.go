@@ -15,9 +15,10 @@ const (
 	missingDefaultModelErrorMessage          = "no default model found (set models[].default: true)"
 	rootConfigurationEmptyContentErrorFormat = "root configuration %s is empty"
 	rootConfigurationUnmarshalErrorFormat    = "unmarshal root configuration %s: %w"
-	mapSortMarshalErrorFormat                = "marshal sort recipe: %w"
-	mapSortUnmarshalErrorFormat              = "map sort recipe: %w"
-	mapChangelogUnmarshalErrorFormat         = "map changelog recipe: %w"
+	mapSortErrorFormat                       = "map sort recipe %q: %w"
+	mapChangelogErrorFormat                  = "map changelog recipe %q: %w"
+	sortRecipeType                           = "task/sort"
+	changelogRecipeType                      = "task/changelog"
 )
 
 type Root struct {
@@ -42,13 +43,28 @@ type Common struct {
 }
 
 type Model struct {
-	Name                string  `yaml:"name"`
-	Provider            string  `yaml:"provider"`
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider"`
+	// Endpoint and APIKeyEnv override common.api for this model (e.g. a local Ollama server).
+	Endpoint            string  `yaml:"endpoint"`
+	APIKeyEnv           string  `yaml:"api_key_env"`
 	ModelID             string  `yaml:"model_id"`
 	Default             bool    `yaml:"default"`
 	SupportsTemperature bool    `yaml:"supports_temperature"`
 	DefaultTemperature  float64 `yaml:"default_temperature"`
 	MaxCompletionTokens int     `yaml:"max_completion_tokens"`
+	Pricing             Pricing `yaml:"pricing"`
+}
+
+// Pricing holds per-1K-token prices used to estimate run cost; zero means unknown.
+type Pricing struct {
+	PromptPer1K     float64 `yaml:"prompt_per_1k"`
+	CompletionPer1K float64 `yaml:"completion_per_1k"`
+}
+
+// Cost estimates the price of the given prompt and completion token counts.
+func (p Pricing) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1000*p.PromptPer1K + float64(completionTokens)/1000*p.CompletionPer1K
 }
 
 type Recipe struct {
@@ -58,28 +74,77 @@ type Recipe struct {
 	Type    string `yaml:"type"`
 
 	Body map[string]any `yaml:",inline"`
+
+	// node is the recipe as parsed from the file; MapSort and MapChangelog decode it so
+	// schema errors carry the position in config.yaml.
+	node *yaml.Node
+}
+
+// UnmarshalYAML keeps the recipe's node next to the decoded fields.
+func (r *Recipe) UnmarshalYAML(value *yaml.Node) error {
+	type plainRecipe Recipe
+	var decoded plainRecipe
+	if err := value.Decode(&decoded); err != nil {
+		return err
+	}
+	*r = Recipe(decoded)
+	r.node = value
+	return nil
 }
 
 // LoadRoot parses the provided configuration source and validates required fields.
 func LoadRoot(source RootConfigurationSource) (Root, error) {
+	rootConfiguration, err := DecodeRoot(source)
+	if err != nil {
+		return Root{}, err
+	}
+	if err := rootConfiguration.CheckModels(); err != nil {
+		return Root{}, err
+	}
+	return rootConfiguration, nil
+}
+
+// DecodeRoot parses the provided configuration source and checks every recipe against its
+// schema, but leaves the models to CheckModels so a caller can report both.
+func DecodeRoot(source RootConfigurationSource) (Root, error) {
 	if len(source.Content) == 0 {
 		return Root{}, fmt.Errorf(rootConfigurationEmptyContentErrorFormat, source.Reference)
 	}
 
 	var rootConfiguration Root
-	if err := yaml.Unmarshal(source.Content, &rootConfiguration); err != nil {
+	if err := decodeStrict(source.Content, &rootConfiguration); err != nil {
 		return Root{}, fmt.Errorf(rootConfigurationUnmarshalErrorFormat, source.Reference, err)
 	}
-
-	if len(rootConfiguration.Models) == 0 {
-		return Root{}, errors.New(emptyModelsErrorMessage)
+	var recipeErrs []error
+	for _, recipe := range rootConfiguration.Recipes {
+		var recipeErr error
+		switch recipe.Type {
+		case sortRecipeType:
+			_, recipeErr = MapSort(recipe)
+		case changelogRecipeType:
+			_, recipeErr = MapChangelog(recipe)
+		}
+		if recipeErr != nil {
+			recipeErrs = append(recipeErrs, recipeErr)
+		}
 	}
-	if _, ok := rootConfiguration.DefaultModel(); !ok {
-		return Root{}, errors.New(missingDefaultModelErrorMessage)
+	if len(recipeErrs) > 0 {
+		return Root{}, fmt.Errorf(rootConfigurationUnmarshalErrorFormat, source.Reference, errors.Join(recipeErrs...))
 	}
 	return rootConfiguration, nil
 }
 
+// CheckModels reports an empty models list or a missing default model.
+func (root Root) CheckModels() error {
+	if len(root.Models) == 0 {
+		return errors.New(emptyModelsErrorMessage)
+	}
+	if _, ok := root.DefaultModel(); !ok {
+		return errors.New(missingDefaultModelErrorMessage)
+	}
+	return nil
+}
+
 func (root Root) DefaultModel() (Model, bool) {
 	for _, modelConfiguration := range root.Models {
 		if modelConfiguration.Default {
@@ -107,35 +172,11 @@ func (root Root) FindRecipe(name string) (Recipe, bool) {
 	return Recipe{}, false
 }
 
-type SortYAML struct {
-	Grant struct {
-		BaseDirectories struct {
-			Downloads string `yaml:"downloads"`
-			Staging   string `yaml:"staging"`
-		} `yaml:"base_directories"`
-		Safety struct {
-			DryRun bool `yaml:"dry_run"`
-		} `yaml:"safety"`
-	} `yaml:"grant"`
-	Projects []struct {
-		Name     string   `yaml:"name"`
-		Target   string   `yaml:"target"`
-		Keywords []string `yaml:"keywords"`
-	} `yaml:"projects"`
-	Thresholds struct {
-		MinConfidence float64 `yaml:"min_confidence"`
-	} `yaml:"thresholds"`
-}
-
-// MapSort converts a recipe into the SortYAML structure expected by the sort task.
-func MapSort(recipe Recipe) (SortYAML, error) {
-	var sortConfiguration SortYAML
-	encodedRecipeBody, marshalError := yaml.Marshal(recipe.Body)
-	if marshalError != nil {
-		return sortConfiguration, fmt.Errorf(mapSortMarshalErrorFormat, marshalError)
-	}
-	if err := yaml.Unmarshal(encodedRecipeBody, &sortConfiguration); err != nil {
-		return sortConfiguration, fmt.Errorf(mapSortUnmarshalErrorFormat, err)
+// MapSort decodes a sort recipe into the Sort schema, rejecting keys the schema does not define.
+func MapSort(recipe Recipe) (Sort, error) {
+	var sortConfiguration Sort
+	if err := decodeRecipe(recipe, &sortConfiguration); err != nil {
+		return Sort{}, fmt.Errorf(mapSortErrorFormat, recipe.Name, err)
 	}
 	return sortConfiguration, nil
 }
@@ -159,8 +200,13 @@ type ChangelogConfig struct {
 			Default  string `yaml:"default"`
 		} `yaml:"date"`
 		GitLog struct {
-			Required bool   `yaml:"required"`
+			Required bool `yaml:"required"`
+			// Source is "stdin" (a piped git log) or "git" (collected from RepoPath between From and To).
 			Source   string `yaml:"source"`
+			RepoPath string `yaml:"repo_path"`
+			// From defaults to the latest tag reachable from To; To defaults to HEAD.
+			From string `yaml:"from"`
+			To   string `yaml:"to"`
 		} `yaml:"git_log"`
 	} `yaml:"inputs"`
 	Recipe struct {
@@ -173,25 +219,35 @@ type ChangelogConfig struct {
 				Max   int    `yaml:"max"`
 			} `yaml:"sections"`
 			Footer string `yaml:"footer"`
+			// BreakingFooter replaces Footer when a commit is marked breaking (type! or BREAKING CHANGE:).
+			BreakingFooter string `yaml:"breaking_footer"`
 		} `yaml:"format"`
-		Rules []string `yaml:"rules"`
+		// Mapping assigns Conventional Commit types (feat, fix, docs, ...) to format.sections titles.
+		// When set, commits are pre-grouped into sections before prompting.
+		Mapping map[string]string `yaml:"mapping"`
+		Rules   []string          `yaml:"rules"`
 	} `yaml:"recipe"`
 	Apply struct {
-		OutputPath      string `yaml:"output_path"`
+		OutputPath string `yaml:"output_path"`
+		// Mode is prepend, upsert, replace, print, json, github or debian.
 		Mode            string `yaml:"mode"`
 		EnsureBlankLine bool   `yaml:"ensure_blank_line"`
+		// Debian fills the entry header and trailer for mode "debian".
+		Debian struct {
+			Package      string `yaml:"package"`
+			Distribution string `yaml:"distribution"`
+			Urgency      string `yaml:"urgency"`
+			// Maintainer is "Name <email>"; it defaults to $DEBFULLNAME <$DEBEMAIL> like dch.
+			Maintainer string `yaml:"maintainer"`
+		} `yaml:"debian"`
 	} `yaml:"apply"`
 }
 
-// MapChangelog converts a recipe into the changelog task configuration schema.
+// MapChangelog decodes a changelog recipe into the ChangelogConfig schema, rejecting unknown keys.
 func MapChangelog(recipe Recipe) (ChangelogConfig, error) {
 	var changelogConfiguration ChangelogConfig
-	encodedRecipeBody, marshalError := yaml.Marshal(recipe.Body)
-	if marshalError != nil {
-		return changelogConfiguration, marshalError
-	}
-	if err := yaml.Unmarshal(encodedRecipeBody, &changelogConfiguration); err != nil {
-		return changelogConfiguration, fmt.Errorf(mapChangelogUnmarshalErrorFormat, err)
+	if err := decodeRecipe(recipe, &changelogConfiguration); err != nil {
+		return ChangelogConfig{}, fmt.Errorf(mapChangelogErrorFormat, recipe.Name, err)
 	}
 	changelogConfiguration.Task = changelogTaskName
 	if changelogConfiguration.LLM.MaxTokens <= 0 {
@@ -210,25 +266,39 @@ type Sort struct {
 			DryRun bool `yaml:"dry_run"`
 		} `yaml:"safety"`
 	} `yaml:"grant"`
-	Projects []struct {
-		Name     string   `yaml:"name"`
-		Target   string   `yaml:"target"`
-		Keywords []string `yaml:"keywords"`
-	} `yaml:"projects"`
+	Projects   []SortProject `yaml:"projects"`
 	Thresholds struct {
 		MinConfidence float64 `yaml:"min_confidence"`
 	} `yaml:"thresholds"`
+	Batching struct {
+		Size        int `yaml:"size"`
+		Concurrency int `yaml:"concurrency"`
+	} `yaml:"batching"`
+	Enrichment struct {
+		Enabled         bool  `yaml:"enabled"`
+		MaxBytesPerFile int64 `yaml:"max_bytes_per_file"`
+	} `yaml:"enrichment"`
+	Preclassify struct {
+		Enabled       bool    `yaml:"enabled"`
+		MinConfidence float64 `yaml:"min_confidence"`
+	} `yaml:"preclassify"`
+	Duplicates struct {
+		// Strategy is one of keep_newest, move_extras, skip; empty disables duplicate detection.
+		Strategy string `yaml:"strategy"`
+	} `yaml:"duplicates"`
+	// LowConfidencePolicy is one of refine (default), route_to_inbox, skip, ask.
+	LowConfidencePolicy string `yaml:"low_confidence_policy"`
 }
 
-// LoadSort reads a legacy sort configuration file from disk.
+// LoadSort reads a legacy sort configuration file from disk, rejecting unknown keys.
 func LoadSort(path string) (Sort, error) {
 	var sortConfiguration Sort
 	data, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
 		return Sort{}, err
 	}
-	if err := yaml.Unmarshal(data, &sortConfiguration); err != nil {
-		return Sort{}, err
+	if err := decodeStrict(data, &sortConfiguration); err != nil {
+		return Sort{}, fmt.Errorf("%s: %w", path, err)
 	}
 	return sortConfiguration, nil
 }
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SortProject is one entry of a sort recipe's projects list.
+type SortProject struct {
+	Name     string   `yaml:"name"`
+	Target   string   `yaml:"target"`
+	Keywords []string `yaml:"keywords"`
+}
+
+// AppendSortProjects inserts projects at the end of the named sort recipe's projects list.
+// The edit is textual: every other line of content, comments and blank lines included, is kept
+// verbatim, and the new entries copy the indentation of the existing ones.
+func AppendSortProjects(content []byte, recipeName string, projects []SortProject) ([]byte, error) {
+	if len(projects) == 0 {
+		return content, nil
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("parse configuration: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, fmt.Errorf("configuration is empty")
+	}
+	recipes := mappingValue(document.Content[0], "recipes")
+	if recipes == nil || recipes.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("configuration has no recipes list")
+	}
+	var recipe *yaml.Node
+	for _, candidate := range recipes.Content {
+		if name := mappingValue(candidate, "name"); name != nil && name.Value == recipeName {
+			recipe = candidate
+			break
+		}
+	}
+	if recipe == nil {
+		return nil, fmt.Errorf("recipe %q not found", recipeName)
+	}
+	list := mappingValue(recipe, "projects")
+	if list == nil || list.Kind != yaml.SequenceNode || list.Style&yaml.FlowStyle != 0 || len(list.Content) == 0 {
+		return nil, fmt.Errorf("recipe %q needs a non-empty block-style projects list to append to", recipeName)
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	first := list.Content[0]
+	itemPrefix := lines[first.Line-1][:first.Column-1]
+	if strings.TrimSpace(itemPrefix) != "-" {
+		return nil, fmt.Errorf("recipe %q: projects entries must start on their dash line", recipeName)
+	}
+	fieldPrefix := strings.Repeat(" ", len(itemPrefix))
+
+	var inserted bytes.Buffer
+	for _, project := range projects {
+		keywords := project.Keywords
+		if keywords == nil {
+			keywords = []string{}
+		}
+		encodedKeywords, _ := json.Marshal(keywords)
+		fmt.Fprintf(&inserted, "%sname: %s\n", itemPrefix, quoteScalar(project.Name))
+		fmt.Fprintf(&inserted, "%starget: %s\n", fieldPrefix, quoteScalar(project.Target))
+		fmt.Fprintf(&inserted, "%skeywords: %s\n", fieldPrefix, encodedKeywords)
+	}
+
+	insertAt := lastLine(list.Content[len(list.Content)-1])
+	if insertAt >= len(lines) {
+		insertAt = len(lines)
+	}
+	if insertAt > 0 && !strings.HasSuffix(lines[insertAt-1], "\n") {
+		lines[insertAt-1] += "\n"
+	}
+	var out bytes.Buffer
+	for _, line := range lines[:insertAt] {
+		out.WriteString(line)
+	}
+	out.Write(inserted.Bytes())
+	for _, line := range lines[insertAt:] {
+		out.WriteString(line)
+	}
+	return out.Bytes(), nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lastLine is the last 1-based line occupied by node or any of its children.
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		line = max(line, lastLine(child))
+	}
+	return line
+}
+
+// quoteScalar renders a double-quoted YAML scalar; JSON string escaping is valid YAML.
+func quoteScalar(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
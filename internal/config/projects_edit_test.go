@@ -0,0 +1,125 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/config"
+)
+
+const projectsEditConfiguration = `# llm-tasks configuration
+models:
+  - name: default
+    default: true
+recipes:
+  - name: sort
+    type: task/sort
+    projects:
+      # hand-curated projects
+      - name: "3D_Printing"
+        target: "3D_Printing"
+        keywords: ["stl","3mf"]   # slicer files
+      - name: Taxes
+        target: Taxes
+        keywords:
+          - w2
+          - "1099"
+
+    thresholds:
+      min_confidence: 0.6   # keep in sync with the team
+  - name: other
+    type: task/sort
+    projects: []
+`
+
+func TestAppendSortProjects(t *testing.T) {
+	testCases := []struct {
+		name          string
+		recipe        string
+		projects      []config.SortProject
+		expected      string
+		expectedError string
+	}{
+		{
+			name:   "appends after last entry and keeps comments",
+			recipe: "sort",
+			projects: []config.SortProject{
+				{Name: "Board Games", Target: "Board_Games", Keywords: []string{"rulebook", "meeple"}},
+				{Name: "Quote\"d", Target: "Quoted"},
+			},
+			expected: `# llm-tasks configuration
+models:
+  - name: default
+    default: true
+recipes:
+  - name: sort
+    type: task/sort
+    projects:
+      # hand-curated projects
+      - name: "3D_Printing"
+        target: "3D_Printing"
+        keywords: ["stl","3mf"]   # slicer files
+      - name: Taxes
+        target: Taxes
+        keywords:
+          - w2
+          - "1099"
+      - name: "Board Games"
+        target: "Board_Games"
+        keywords: ["rulebook","meeple"]
+      - name: "Quote\"d"
+        target: "Quoted"
+        keywords: []
+
+    thresholds:
+      min_confidence: 0.6   # keep in sync with the team
+  - name: other
+    type: task/sort
+    projects: []
+`,
+		},
+		{
+			name:          "unknown recipe",
+			recipe:        "missing",
+			projects:      []config.SortProject{{Name: "X", Target: "X"}},
+			expectedError: `recipe "missing" not found`,
+		},
+		{
+			name:          "flow style list is not edited",
+			recipe:        "other",
+			projects:      []config.SortProject{{Name: "X", Target: "X"}},
+			expectedError: "non-empty block-style projects list",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			updated, err := config.AppendSortProjects([]byte(projectsEditConfiguration), testCase.recipe, testCase.projects)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			if string(updated) != testCase.expected {
+				t.Fatalf("unexpected configuration:\n%s", updated)
+			}
+
+			root, loadErr := config.LoadRoot(config.RootConfigurationSource{Reference: "test", Content: updated})
+			if loadErr != nil {
+				t.Fatalf("reload: %v", loadErr)
+			}
+			recipe, _ := root.FindRecipe(testCase.recipe)
+			sortConfiguration, mapErr := config.MapSort(recipe)
+			if mapErr != nil {
+				t.Fatalf("map sort: %v", mapErr)
+			}
+			if len(sortConfiguration.Projects) != 4 || sortConfiguration.Projects[3].Name != `Quote"d` {
+				t.Fatalf("unexpected projects after reload: %+v", sortConfiguration.Projects)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// recipeHeaderKeys are the Recipe fields every recipe carries next to its type-specific body.
+var recipeHeaderKeys = map[string]bool{"name": true, "enabled": true, "model": true, "type": true}
+
+// unknownFieldPattern matches the error yaml.v3 reports for a key that KnownFields rejects.
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (.+?) not found in type `)
+
+// decodeStrict decodes a YAML document into out with KnownFields(true) and reports every
+// unknown key with its line, column and dotted path.
+func decodeStrict(data []byte, out any) error {
+	return decodeKnownFields(data, out, nil)
+}
+
+// decodeRecipe decodes a recipe body into its typed schema. The body is encoded without the
+// recipe header keys and decoded strictly; unknown keys are reported at their position in the
+// file. Recipes built in code rather than parsed from a file have no useful position.
+func decodeRecipe(recipe Recipe, out any) error {
+	original := recipe.node
+	if original == nil {
+		original = &yaml.Node{}
+		if err := original.Encode(recipe.Body); err != nil {
+			return err
+		}
+	}
+	body := *original
+	if body.Kind == yaml.MappingNode {
+		body.Content = nil
+		for index := 0; index+1 < len(original.Content); index += 2 {
+			if !recipeHeaderKeys[original.Content[index].Value] {
+				body.Content = append(body.Content, original.Content[index], original.Content[index+1])
+			}
+		}
+	}
+	data, err := yaml.Marshal(&body)
+	if err != nil {
+		return err
+	}
+	return decodeKnownFields(data, out, original)
+}
+
+// decodeKnownFields decodes data into out, rejecting keys out has no field for. Positions are
+// taken from original when data was encoded from it, and from data itself otherwise.
+func decodeKnownFields(data []byte, out any, original *yaml.Node) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(out)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	var decoded yaml.Node
+	if yaml.Unmarshal(data, &decoded) != nil {
+		return err
+	}
+	if original == nil {
+		original = &decoded
+	}
+
+	problems := make([]error, 0, len(typeErr.Errors))
+	for _, message := range typeErr.Errors {
+		problems = append(problems, describeUnknownField(message, &decoded, original))
+	}
+	return errors.Join(problems...)
+}
+
+// describeUnknownField rewrites yaml.v3's "line N: field x not found in type T" as the key's
+// position in original and its dotted path; other messages are kept as they are.
+func describeUnknownField(message string, decoded, original *yaml.Node) error {
+	match := unknownFieldPattern.FindStringSubmatch(message)
+	if match == nil {
+		return errors.New(message)
+	}
+	line, _ := strconv.Atoi(match[1])
+	path, found := keyPath(decoded, line, match[2])
+	if !found {
+		return errors.New(message)
+	}
+	key := lookupKey(original, path)
+	if key == nil {
+		return errors.New(message)
+	}
+	return fmt.Errorf("line %d, column %d: unknown field %q", key.Line, key.Column, formatYAMLPath(path))
+}
+
+// keyPath finds the mapping key named name on line and returns the path to it; sequence
+// items appear as "[index]" segments.
+func keyPath(node *yaml.Node, line int, name string) ([]string, bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return keyPath(node.Content[0], line, name)
+		}
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key, value := node.Content[index], node.Content[index+1]
+			if key.Line == line && key.Value == name {
+				return []string{key.Value}, true
+			}
+			if path, found := keyPath(value, line, name); found {
+				return append([]string{key.Value}, path...), true
+			}
+		}
+	case yaml.SequenceNode:
+		for index, item := range node.Content {
+			if path, found := keyPath(item, line, name); found {
+				return append([]string{fmt.Sprintf("[%d]", index)}, path...), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// lookupKey follows path through node and returns the key node of its last segment.
+func lookupKey(node *yaml.Node, path []string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	segment, rest := path[0], path[1:]
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			if key := node.Content[index]; key.Value == segment {
+				if len(rest) == 0 {
+					return key
+				}
+				return lookupKey(node.Content[index+1], rest)
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(strings.Trim(segment, "[]"))
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return lookupKey(node.Content[index], rest)
+		}
+	}
+	return nil
+}
+
+func formatYAMLPath(path []string) string {
+	var out strings.Builder
+	for _, segment := range path {
+		if out.Len() > 0 && !strings.HasPrefix(segment, "[") {
+			out.WriteString(".")
+		}
+		out.WriteString(segment)
+	}
+	return out.String()
+}
@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/config"
+)
+
+const strictRootTemplate = `models:
+  - name: default
+    provider: openai
+    model_id: model
+    default: true
+recipes:
+  - name: sort
+    enabled: true
+    type: task/sort
+    grant:
+      base_directories:
+        downloads: /downloads
+        staging: /staging
+    thresholds:
+      min_confidence: 0.7
+  - name: changelog
+    enabled: true
+    type: task/changelog
+    recipe:
+      format:
+        heading: "## [${version}]"
+`
+
+func TestLoadRootRejectsUnknownKeys(t *testing.T) {
+	testCases := []struct {
+		name          string
+		replace       string
+		with          string
+		expectedError string
+	}{
+		{
+			name:          "valid",
+			replace:       "",
+			with:          "",
+			expectedError: "",
+		},
+		{
+			name:          "sort recipe typo",
+			replace:       "      min_confidence: 0.7",
+			with:          "      min_confidance: 0.7",
+			expectedError: `map sort recipe "sort": line 15, column 7: unknown field "thresholds.min_confidance"`,
+		},
+		{
+			name:          "sort recipe typo inside a list",
+			replace:       "    thresholds:",
+			with:          "    projects:\n      - name: A\n        keyword: [a]\n    thresholds:",
+			expectedError: `map sort recipe "sort": line 16, column 9: unknown field "projects[0].keyword"`,
+		},
+		{
+			name:          "changelog recipe typo",
+			replace:       "        heading:",
+			with:          "        headng:",
+			expectedError: `map changelog recipe "changelog": line 21, column 9: unknown field "recipe.format.headng"`,
+		},
+		{
+			name:          "root typo",
+			replace:       "    model_id: model",
+			with:          "    modelid: model",
+			expectedError: `line 4, column 5: unknown field "models[0].modelid"`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			content := strictRootTemplate
+			if testCase.replace != "" {
+				content = strings.Replace(content, testCase.replace, testCase.with, 1)
+			}
+			root, err := config.LoadRoot(config.RootConfigurationSource{Reference: "config.yaml", Content: []byte(content)})
+			if testCase.expectedError == "" {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				recipe, _ := root.FindRecipe("sort")
+				sortConfiguration, mapErr := config.MapSort(recipe)
+				if mapErr != nil || sortConfiguration.Thresholds.MinConfidence != 0.7 {
+					t.Fatalf("expected min_confidence 0.7, got %v (%v)", sortConfiguration.Thresholds.MinConfidence, mapErr)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLoadSortRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.sort.yaml")
+	if err := os.WriteFile(path, []byte("grant:\n  safety:\n    dryrun: true\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, err := config.LoadSort(path)
+	if err == nil || !strings.Contains(err.Error(), `line 3, column 5: unknown field "grant.safety.dryrun"`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
@@ -1,21 +1,42 @@
 package fsops
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"mime"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/afero"
 )
 
+// File is the read-only handle returned by FS.Open; it supports ranged reads.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+}
+
 // FS is an abstract filesystem used across the app and tests.
 type FS interface {
+	Open(name string) (File, error)
 	ReadFile(name string) ([]byte, error)
 	WriteFile(name string, data []byte, perm os.FileMode) error
+	// AppendFile writes data at the end of name, creating it if needed.
+	AppendFile(name string, data []byte, perm os.FileMode) error
 	Stat(name string) (fs.FileInfo, error)
 	Rename(oldpath, newpath string) error
+	// Remove deletes a file or an empty directory.
+	Remove(name string) error
+	// Copy writes src to a new file dst (which must not exist), preserving mode and mtime.
+	Copy(src, dst string) error
 	MkdirAll(path string, perm os.FileMode) error
 	WalkDir(root string, fn fs.WalkDirFunc) error
 
@@ -32,16 +53,76 @@ type OS struct{}
 
 func NewOS() OS { return OS{} }
 
+func (OS) Open(name string) (File, error) {
+	f, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
 func (OS) ReadFile(name string) ([]byte, error) { return os.ReadFile(filepath.Clean(name)) }
 func (OS) WriteFile(name string, b []byte, p os.FileMode) error {
 	return os.WriteFile(filepath.Clean(name), b, p)
 }
+func (OS) AppendFile(name string, b []byte, p os.FileMode) error {
+	f, err := os.OpenFile(filepath.Clean(name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, p)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
 func (OS) Stat(name string) (fs.FileInfo, error)     { return os.Stat(filepath.Clean(name)) }
 func (OS) Rename(a, b string) error                  { return os.Rename(a, b) }
+func (OS) Remove(name string) error                  { return os.Remove(filepath.Clean(name)) }
 func (OS) MkdirAll(path string, p os.FileMode) error { return os.MkdirAll(filepath.Clean(path), p) }
 func (OS) WalkDir(root string, fn fs.WalkDirFunc) error {
 	return filepath.WalkDir(filepath.Clean(root), fn)
 }
+
+// Copy streams src into dst, fsyncs it, checks the copied size and restores mode and mtime.
+// A partially written dst is removed on failure.
+func (OS) Copy(src, dst string) (err error) {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(filepath.Clean(dst), os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(filepath.Clean(dst))
+		}
+	}()
+	written, err := io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != info.Size() {
+		return fmt.Errorf("copy %s: wrote %d of %d bytes", src, written, info.Size())
+	}
+	if err = os.Chmod(filepath.Clean(dst), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(filepath.Clean(dst), info.ModTime(), info.ModTime())
+}
+
 func (OS) Join(elem ...string) string { return filepath.Join(elem...) }
 func (OS) Base(name string) string    { return filepath.Base(name) }
 func (OS) Dir(name string) string     { return filepath.Dir(name) }
@@ -54,12 +135,47 @@ type Mem struct{ Fs afero.Fs }
 
 func NewMem() Mem { return Mem{Fs: afero.NewMemMapFs()} }
 
+func (m Mem) Open(name string) (File, error)       { return m.Fs.Open(filepath.Clean(name)) }
 func (m Mem) ReadFile(name string) ([]byte, error) { return afero.ReadFile(m.Fs, filepath.Clean(name)) }
 func (m Mem) WriteFile(name string, b []byte, p os.FileMode) error {
 	return afero.WriteFile(m.Fs, filepath.Clean(name), b, p)
 }
+func (m Mem) AppendFile(name string, b []byte, p os.FileMode) error {
+	f, err := m.Fs.OpenFile(filepath.Clean(name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, p)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
 func (m Mem) Stat(name string) (fs.FileInfo, error) { return m.Fs.Stat(filepath.Clean(name)) }
 func (m Mem) Rename(a, b string) error              { return m.Fs.Rename(a, b) }
+func (m Mem) Remove(name string) error              { return m.Fs.Remove(filepath.Clean(name)) }
+func (m Mem) Copy(src, dst string) error {
+	info, err := m.Fs.Stat(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	data, err := afero.ReadFile(m.Fs, filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	out, err := m.Fs.OpenFile(filepath.Clean(dst), os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return m.Fs.Chtimes(filepath.Clean(dst), info.ModTime(), info.ModTime())
+}
 func (m Mem) MkdirAll(path string, p os.FileMode) error {
 	return m.Fs.MkdirAll(filepath.Clean(path), p)
 }
@@ -97,6 +213,7 @@ type FileInfo struct {
 	Extension    string
 	MIMEType     string
 	SizeBytes    int64
+	ModTime      time.Time
 }
 
 // Inventory walks a root directory and returns basic file metadata.
@@ -140,12 +257,62 @@ func (o Ops) Inventory(root string) ([]FileInfo, error) {
 			Extension:    ext,
 			MIMEType:     m,
 			SizeBytes:    info.Size(),
+			ModTime:      info.ModTime(),
 		})
 		return nil
 	})
 	return out, err
 }
 
-func (o Ops) EnsureDir(path string) error    { return o.FS.MkdirAll(filepath.Dir(path), 0o755) }
-func (o Ops) MoveFile(from, to string) error { return o.FS.Rename(from, to) }
-func (o Ops) FileExists(p string) bool       { _, err := o.FS.Stat(p); return err == nil }
+// ReadRange reads up to n bytes starting at offset; a short read at end of file is not an error.
+func (o Ops) ReadRange(path string, offset, n int64) ([]byte, error) {
+	f, err := o.FS.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	buf := make([]byte, n)
+	read, err := f.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// HashFile returns the hex-encoded SHA-256 of the file's content.
+func (o Ops) HashFile(path string) (string, error) {
+	f, err := o.FS.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (o Ops) EnsureDir(path string) error { return o.FS.MkdirAll(filepath.Dir(path), 0o755) }
+func (o Ops) FileExists(p string) bool    { _, err := o.FS.Stat(p); return err == nil }
+
+// MoveFile renames from to to. When they are on different devices (EXDEV) it falls back to
+// copying, verifying the copy's checksum against the source and only then removing the source.
+func (o Ops) MoveFile(from, to string) error {
+	err := o.FS.Rename(from, to)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := o.FS.Copy(from, to); err != nil {
+		return fmt.Errorf("cross-device move %s: %w", from, err)
+	}
+	sourceHash, err := o.HashFile(from)
+	if err != nil {
+		return errors.Join(fmt.Errorf("cross-device move %s: hash source: %w", from, err), o.FS.Remove(to))
+	}
+	copyHash, err := o.HashFile(to)
+	if err != nil || copyHash != sourceHash {
+		return errors.Join(fmt.Errorf("cross-device move %s: copy does not match source", from), err, o.FS.Remove(to))
+	}
+	return o.FS.Remove(from)
+}
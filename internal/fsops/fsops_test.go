@@ -1,7 +1,11 @@
 package fsops_test
 
 import (
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/temirov/llm-tasks/internal/fsops"
 )
@@ -56,3 +60,136 @@ func TestInventoryAndOps_InMemory(t *testing.T) {
 		t.Fatalf("dst should exist after move")
 	}
 }
+
+func TestReadRange_InMemory(t *testing.T) {
+	mem := fsops.NewMem()
+	fs := fsops.NewOps(mem)
+	if err := mem.WriteFile("/data.bin", []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write data: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		offset int64
+		length int64
+		want   string
+	}{
+		{name: "head", offset: 0, length: 4, want: "0123"},
+		{name: "middle", offset: 3, length: 3, want: "345"},
+		{name: "short read at end", offset: 8, length: 10, want: "89"},
+		{name: "past end", offset: 20, length: 4, want: ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := fs.ReadRange("/data.bin", testCase.offset, testCase.length)
+			if err != nil {
+				t.Fatalf("ReadRange: %v", err)
+			}
+			if string(got) != testCase.want {
+				t.Fatalf("expected %q, got %q", testCase.want, string(got))
+			}
+		})
+	}
+}
+
+// crossDeviceFS simulates staging on another mount: every rename fails with EXDEV.
+type crossDeviceFS struct {
+	fsops.Mem
+	corruptCopy bool
+}
+
+func (c crossDeviceFS) Rename(a, b string) error {
+	return &os.LinkError{Op: "rename", Old: a, New: b, Err: syscall.EXDEV}
+}
+
+func (c crossDeviceFS) Copy(src, dst string) error {
+	if err := c.Mem.Copy(src, dst); err != nil || !c.corruptCopy {
+		return err
+	}
+	return c.Mem.WriteFile(dst, []byte("garbage"), 0o644)
+}
+
+func TestMoveFile_CrossDeviceFallback(t *testing.T) {
+	modTime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		corruptCopy bool
+		expectError bool
+	}{
+		{name: "verified copy removes source"},
+		{name: "mismatched copy keeps source", corruptCopy: true, expectError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mem := fsops.NewMem()
+			if err := mem.WriteFile("/downloads/a.bin", []byte("payload"), 0o640); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := mem.Fs.Chtimes("/downloads/a.bin", modTime, modTime); err != nil {
+				t.Fatalf("chtimes: %v", err)
+			}
+			if err := mem.MkdirAll("/staging", 0o755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			ops := fsops.NewOps(crossDeviceFS{Mem: mem, corruptCopy: testCase.corruptCopy})
+
+			err := ops.MoveFile("/downloads/a.bin", "/staging/a.bin")
+			if testCase.expectError {
+				if err == nil {
+					t.Fatal("expected an error for a mismatched copy")
+				}
+				if !ops.FileExists("/downloads/a.bin") || ops.FileExists("/staging/a.bin") {
+					t.Fatal("expected the source to stay and the bad copy to be removed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MoveFile: %v", err)
+			}
+			if ops.FileExists("/downloads/a.bin") {
+				t.Fatal("expected the source to be removed")
+			}
+			info, err := mem.Stat("/staging/a.bin")
+			if err != nil {
+				t.Fatalf("stat copy: %v", err)
+			}
+			if info.Mode().Perm() != 0o640 || !info.ModTime().Equal(modTime) {
+				t.Fatalf("expected mode 0640 and mtime %v, got %v and %v", modTime, info.Mode().Perm(), info.ModTime())
+			}
+		})
+	}
+}
+
+func TestOSCopyPreservesModeAndModTime(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	modTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	osfs := fsops.NewOS()
+	if err := osfs.Copy(src, dst); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 || !info.ModTime().Equal(modTime) || info.Size() != 5 {
+		t.Fatalf("unexpected copy metadata: mode=%v mtime=%v size=%d", info.Mode().Perm(), info.ModTime(), info.Size())
+	}
+	if err := osfs.Copy(src, dst); err == nil {
+		t.Fatal("expected Copy to refuse an existing destination")
+	}
+	if err := osfs.Remove(src); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be removed, got %v", err)
+	}
+}
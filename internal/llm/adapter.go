@@ -2,17 +2,25 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/temirov/llm-tasks/internal/pipeline"
 )
 
+const structuredOutputSchemaName = "response"
+
 type Adapter struct {
-	Client              Client
+	Client              ChatCompleter
 	DefaultModel        string
 	DefaultTemp         float64
 	DefaultTokens       int
 	SupportsTemperature bool
+	// Logger receives per-call debug events; nil disables logging.
+	Logger *zap.Logger
 }
 
 func (a Adapter) Chat(ctx context.Context, req pipeline.LLMRequest) (pipeline.LLMResponse, error) {
@@ -28,18 +36,47 @@ func (a Adapter) Chat(ctx context.Context, req pipeline.LLMRequest) (pipeline.LL
 		tempPtr = &t
 	}
 
+	messages := []ChatMessage{{Role: "system", Content: strings.TrimSpace(req.SystemPrompt)}, {Role: "user", Content: strings.TrimSpace(req.UserPrompt)}}
+	for _, m := range req.Messages {
+		messages = append(messages, ChatMessage{Role: m.Role, Content: strings.TrimSpace(m.Content)})
+	}
+
 	cr := ChatCompletionRequest{
 		Model:               model,
-		Messages:            []ChatMessage{{Role: "system", Content: strings.TrimSpace(req.SystemPrompt)}, {Role: "user", Content: strings.TrimSpace(req.UserPrompt)}},
+		Messages:            messages,
 		MaxCompletionTokens: chooseInt(req.MaxTokens, a.DefaultTokens),
 		Temperature:         tempPtr, // omitted if nil
 	}
+	if len(req.JSONSchema) > 0 {
+		cr.ResponseFormat = &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchemaFormat{
+				Name:   structuredOutputSchemaName,
+				Schema: json.RawMessage(req.JSONSchema),
+				Strict: true,
+			},
+		}
+	}
 
+	logger := a.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	started := time.Now()
 	out, err := a.Client.CreateChatCompletion(ctx, cr)
 	if err != nil {
+		logger.Debug("chat completion failed", zap.String("model", model), zap.Duration("latency", time.Since(started)), zap.Error(err))
 		return pipeline.LLMResponse{}, err
 	}
-	return pipeline.LLMResponse{RawText: out}, nil
+	logger.Debug("chat completion",
+		zap.String("model", model),
+		zap.Int("messages", len(messages)),
+		zap.Bool("json_schema", cr.ResponseFormat != nil),
+		zap.Duration("latency", time.Since(started)),
+		zap.Int("prompt_tokens", out.Usage.PromptTokens),
+		zap.Int("completion_tokens", out.Usage.CompletionTokens),
+	)
+	return pipeline.LLMResponse{RawText: out.Content, Usage: out.Usage}, nil
 }
 
 func chooseInt(a, b int) int {
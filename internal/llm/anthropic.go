@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const (
+	anthropicAPIVersion        = "2023-06-01"
+	anthropicDefaultMaxTokens  = 1024
+	anthropicTextContentType   = "text"
+	anthropicSystemMessageRole = "system"
+)
+
+// AnthropicClient talks to the Anthropic Messages API.
+// It accepts the same ChatCompletionRequest as Client so Adapter stays provider-agnostic;
+// ResponseFormat is not supported by the API and is left to the pipeline's local schema check.
+type AnthropicClient struct {
+	HTTPBaseURL string
+	APIKey      string
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (c AnthropicClient) CreateChatCompletion(ctx context.Context, requestPayload ChatCompletionRequest) (ChatCompletionResult, error) {
+	payload := anthropicRequest{
+		Model:       requestPayload.Model,
+		MaxTokens:   chooseInt(requestPayload.MaxCompletionTokens, anthropicDefaultMaxTokens),
+		Temperature: requestPayload.Temperature,
+	}
+	var systemParts []string
+	for _, message := range requestPayload.Messages {
+		if message.Role == anthropicSystemMessageRole {
+			if strings.TrimSpace(message.Content) != "" {
+				systemParts = append(systemParts, message.Content)
+			}
+			continue
+		}
+		payload.Messages = append(payload.Messages, anthropicMessage{Role: message.Role, Content: message.Content})
+	}
+	payload.System = strings.Join(systemParts, "\n\n")
+
+	requestBytes, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return ChatCompletionResult{}, marshalErr
+	}
+	httpRequest, buildErr := http.NewRequestWithContext(ctx, http.MethodPost, c.HTTPBaseURL+"/messages", bytes.NewReader(requestBytes))
+	if buildErr != nil {
+		return ChatCompletionResult{}, buildErr
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("x-api-key", c.APIKey)
+	httpRequest.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	httpClient := &http.Client{}
+	httpResponse, httpErr := httpClient.Do(httpRequest)
+	if httpErr != nil {
+		return ChatCompletionResult{}, httpErr
+	}
+	defer func(closer io.ReadCloser) { _ = closer.Close() }(httpResponse.Body)
+
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(httpResponse.Body)
+		return ChatCompletionResult{}, fmt.Errorf("llm http error %d: %s", httpResponse.StatusCode, string(bodyBytes))
+	}
+
+	var completion anthropicResponse
+	decodeErr := json.NewDecoder(httpResponse.Body).Decode(&completion)
+	if decodeErr != nil {
+		return ChatCompletionResult{}, decodeErr
+	}
+	var text strings.Builder
+	for _, block := range completion.Content {
+		if block.Type == anthropicTextContentType {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return ChatCompletionResult{}, fmt.Errorf("empty completion")
+	}
+	return ChatCompletionResult{
+		Content: text.String(),
+		Usage: pipeline.Usage{
+			PromptTokens:     completion.Usage.InputTokens,
+			CompletionTokens: completion.Usage.OutputTokens,
+			TotalTokens:      completion.Usage.InputTokens + completion.Usage.OutputTokens,
+		},
+	}, nil
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const (
+	cassetteFileExtension   = ".json"
+	cassetteDirectoryMode   = 0o755
+	cassetteFileMode        = 0o644
+	cassetteMissingErrorFmt = "replay: no recorded response for request %s in %s"
+)
+
+// CassetteEntry is one recorded request/response pair, stored as <key>.json in a cassette directory.
+type CassetteEntry struct {
+	Key      string               `json:"key"`
+	Model    CassetteModel        `json:"model"`
+	Request  pipeline.LLMRequest  `json:"request"`
+	Response pipeline.LLMResponse `json:"response"`
+}
+
+// CassetteModel is the model a request is sent to. Tasks usually leave LLMRequest.Model empty
+// and let the adapter fill in models[].model_id, so the key needs it from the caller.
+type CassetteModel struct {
+	Provider string `json:"provider"`
+	ModelID  string `json:"model_id"`
+}
+
+// CassetteKey hashes the model and every field of the request so any prompt, schema or model
+// change misses the cassette.
+func CassetteKey(model CassetteModel, request pipeline.LLMRequest) (string, error) {
+	if request.Model != "" {
+		model.ModelID = request.Model
+	}
+	encoded, err := json.Marshal(struct {
+		Model   CassetteModel       `json:"model"`
+		Request pipeline.LLMRequest `json:"request"`
+	}{model, request})
+	if err != nil {
+		return "", fmt.Errorf("encode request: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RecordingClient forwards requests to Next and saves every successful exchange under Dir.
+type RecordingClient struct {
+	Next  pipeline.LLMClient
+	Dir   string
+	Model CassetteModel
+}
+
+func (c RecordingClient) Chat(ctx context.Context, request pipeline.LLMRequest) (pipeline.LLMResponse, error) {
+	response, chatErr := c.Next.Chat(ctx, request)
+	if chatErr != nil {
+		return pipeline.LLMResponse{}, chatErr
+	}
+	key, keyErr := CassetteKey(c.Model, request)
+	if keyErr != nil {
+		return pipeline.LLMResponse{}, keyErr
+	}
+	encoded, encodeErr := json.MarshalIndent(CassetteEntry{Key: key, Model: c.Model, Request: request, Response: response}, "", "  ")
+	if encodeErr != nil {
+		return pipeline.LLMResponse{}, fmt.Errorf("record: encode entry: %w", encodeErr)
+	}
+	if err := os.MkdirAll(filepath.Clean(c.Dir), cassetteDirectoryMode); err != nil {
+		return pipeline.LLMResponse{}, fmt.Errorf("record: %w", err)
+	}
+	if err := os.WriteFile(cassettePath(c.Dir, key), encoded, cassetteFileMode); err != nil {
+		return pipeline.LLMResponse{}, fmt.Errorf("record: %w", err)
+	}
+	return response, nil
+}
+
+// ReplayClient serves responses recorded by RecordingClient without touching the network.
+type ReplayClient struct {
+	Dir   string
+	Model CassetteModel
+}
+
+func (c ReplayClient) Chat(ctx context.Context, request pipeline.LLMRequest) (pipeline.LLMResponse, error) {
+	key, keyErr := CassetteKey(c.Model, request)
+	if keyErr != nil {
+		return pipeline.LLMResponse{}, keyErr
+	}
+	data, readErr := os.ReadFile(cassettePath(c.Dir, key))
+	if readErr != nil {
+		if errors.Is(readErr, fs.ErrNotExist) {
+			return pipeline.LLMResponse{}, fmt.Errorf(cassetteMissingErrorFmt, key, c.Dir)
+		}
+		return pipeline.LLMResponse{}, fmt.Errorf("replay: %w", readErr)
+	}
+	var entry CassetteEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return pipeline.LLMResponse{}, fmt.Errorf("replay: decode %s: %w", key, err)
+	}
+	return entry.Response, nil
+}
+
+func cassettePath(dir, key string) string {
+	return filepath.Join(filepath.Clean(dir), key+cassetteFileExtension)
+}
@@ -0,0 +1,68 @@
+package llm_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/llm"
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+type countingClient struct{ calls int }
+
+func (c *countingClient) Chat(ctx context.Context, req pipeline.LLMRequest) (pipeline.LLMResponse, error) {
+	c.calls++
+	return pipeline.LLMResponse{RawText: "answer to " + req.UserPrompt}, nil
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassette")
+	upstream := &countingClient{}
+	model := llm.CassetteModel{Provider: "openai", ModelID: "gpt-5-mini"}
+	recorder := llm.RecordingClient{Next: upstream, Dir: dir, Model: model}
+
+	first := pipeline.LLMRequest{SystemPrompt: "sys", UserPrompt: "one", JSONSchema: []byte(`{"type":"object"}`)}
+	second := pipeline.LLMRequest{
+		SystemPrompt: "sys",
+		UserPrompt:   "one",
+		Messages:     []pipeline.Message{{Role: pipeline.RoleAssistant, Content: "bad"}, {Role: pipeline.RoleUser, Content: "fix"}},
+	}
+	for _, req := range []pipeline.LLMRequest{first, second} {
+		if _, err := recorder.Chat(context.Background(), req); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read cassette dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 cassette entries, got %d", len(entries))
+	}
+
+	replayer := llm.ReplayClient{Dir: dir, Model: model}
+	resp, err := replayer.Chat(context.Background(), first)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if resp.RawText != "answer to one" {
+		t.Fatalf("unexpected replayed response %q", resp.RawText)
+	}
+	if upstream.calls != 2 {
+		t.Fatalf("replay must not reach upstream, calls=%d", upstream.calls)
+	}
+
+	_, err = replayer.Chat(context.Background(), pipeline.LLMRequest{UserPrompt: "never recorded"})
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Fatalf("expected missing cassette error, got %v", err)
+	}
+
+	otherModel := llm.ReplayClient{Dir: dir, Model: llm.CassetteModel{Provider: "openai", ModelID: "gpt-5"}}
+	_, err = otherModel.Chat(context.Background(), first)
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Fatalf("expected a model change to miss the cassette, got %v", err)
+	}
+}
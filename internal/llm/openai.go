@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
 )
 
 type Client struct {
@@ -23,49 +25,84 @@ type ChatMessage struct {
 }
 
 type ChatCompletionRequest struct {
-	Model               string        `json:"model"`
-	Messages            []ChatMessage `json:"messages"`
-	MaxCompletionTokens int           `json:"max_completion_tokens,omitempty"`
-	Temperature         *float64      `json:"temperature,omitempty"`
+	Model               string          `json:"model"`
+	Messages            []ChatMessage   `json:"messages"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat selects OpenAI structured output; Type is "json_schema" when a schema is enforced.
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
 }
 
 type ChatCompletionResponse struct {
 	Choices []struct {
 		Message ChatMessage `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
 }
 
-func (c Client) CreateChatCompletion(ctx context.Context, requestPayload ChatCompletionRequest) (string, error) {
+// ChatCompletionResult is the provider-neutral outcome of one completion call.
+type ChatCompletionResult struct {
+	Content string
+	Usage   pipeline.Usage
+}
+
+func (c Client) CreateChatCompletion(ctx context.Context, requestPayload ChatCompletionRequest) (ChatCompletionResult, error) {
 	requestBytes, marshalErr := json.Marshal(requestPayload)
 	if marshalErr != nil {
-		return "", marshalErr
+		return ChatCompletionResult{}, marshalErr
 	}
 	httpRequest, buildErr := http.NewRequestWithContext(ctx, http.MethodPost, c.HTTPBaseURL+"/chat/completions", bytes.NewReader(requestBytes))
 	if buildErr != nil {
-		return "", buildErr
+		return ChatCompletionResult{}, buildErr
 	}
 	httpRequest.Header.Set("Content-Type", "application/json")
-	httpRequest.Header.Set("Authorization", "Bearer "+c.APIKey)
+	if c.APIKey != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
 
 	httpClient := &http.Client{}
 	httpResponse, httpErr := httpClient.Do(httpRequest)
 	if httpErr != nil {
-		return "", httpErr
+		return ChatCompletionResult{}, httpErr
 	}
 	defer func(closer io.ReadCloser) { _ = closer.Close() }(httpResponse.Body)
 
 	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
 		bodyBytes, _ := io.ReadAll(httpResponse.Body)
-		return "", fmt.Errorf("llm http error %d: %s", httpResponse.StatusCode, string(bodyBytes))
+		return ChatCompletionResult{}, fmt.Errorf("llm http error %d: %s", httpResponse.StatusCode, string(bodyBytes))
 	}
 
 	var completion ChatCompletionResponse
 	decodeErr := json.NewDecoder(httpResponse.Body).Decode(&completion)
 	if decodeErr != nil {
-		return "", decodeErr
+		return ChatCompletionResult{}, decodeErr
 	}
 	if len(completion.Choices) == 0 {
-		return "", fmt.Errorf("empty completion")
+		return ChatCompletionResult{}, fmt.Errorf("empty completion")
+	}
+	usage := pipeline.Usage{
+		PromptTokens:     completion.Usage.PromptTokens,
+		CompletionTokens: completion.Usage.CompletionTokens,
+		TotalTokens:      completion.Usage.TotalTokens,
+	}
+	if usage.TotalTokens == 0 {
+		// some OpenAI-compatible servers omit the total
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
 	}
-	return completion.Choices[0].Message.Content, nil
+	return ChatCompletionResult{Content: completion.Choices[0].Message.Content, Usage: usage}, nil
 }
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderOllama           = "ollama"
+	ProviderAnthropic        = "anthropic"
+
+	openAIDefaultEndpoint    = "https://api.openai.com/v1"
+	openAIDefaultAPIKeyEnv   = "OPENAI_API_KEY"
+	ollamaDefaultEndpoint    = "http://localhost:11434/v1"
+	anthropicDefaultEndpoint = "https://api.anthropic.com/v1"
+	anthropicDefaultKeyEnv   = "ANTHROPIC_API_KEY"
+)
+
+// ChatCompleter is the wire-level client an Adapter sends requests through.
+type ChatCompleter interface {
+	CreateChatCompletion(ctx context.Context, requestPayload ChatCompletionRequest) (ChatCompletionResult, error)
+}
+
+// Endpoint is the resolved connection settings for one model.
+type Endpoint struct {
+	BaseURL string
+	APIKey  string
+}
+
+// Provider describes how to reach one family of LLM APIs.
+type Provider struct {
+	// DefaultEndpoint is used when neither the model nor common.api (if inherited) sets one.
+	DefaultEndpoint string
+	// DefaultAPIKeyEnv is read when neither the model nor common.api (if inherited) names a variable.
+	DefaultAPIKeyEnv string
+	// RequiresAPIKey fails resolution when the API key variable is unset.
+	RequiresAPIKey bool
+	// InheritsCommonAPI lets common.api.endpoint/api_key_env act as fallbacks for this provider.
+	InheritsCommonAPI bool
+	NewClient         func(endpoint Endpoint) ChatCompleter
+}
+
+// EndpointSettings carries the endpoint/key configuration found on a model and in common.api.
+type EndpointSettings struct {
+	ModelEndpoint   string
+	ModelAPIKeyEnv  string
+	CommonEndpoint  string
+	CommonAPIKeyEnv string
+}
+
+// Resolve picks the endpoint and API key for a model, reading the key from the environment.
+func (p Provider) Resolve(settings EndpointSettings, lookup func(string) (string, bool)) (Endpoint, error) {
+	baseURL := strings.TrimSpace(settings.ModelEndpoint)
+	if baseURL == "" && p.InheritsCommonAPI {
+		baseURL = strings.TrimSpace(settings.CommonEndpoint)
+	}
+	if baseURL == "" {
+		baseURL = p.DefaultEndpoint
+	}
+	if baseURL == "" {
+		return Endpoint{}, fmt.Errorf("missing endpoint: set models[].endpoint")
+	}
+
+	keyEnv := p.APIKeyEnv(settings)
+	var apiKey string
+	if keyEnv != "" {
+		value, _ := lookup(keyEnv)
+		apiKey = strings.TrimSpace(value)
+	}
+	if apiKey == "" && p.RequiresAPIKey {
+		return Endpoint{}, fmt.Errorf("missing API key: set %s", keyEnv)
+	}
+	return Endpoint{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey}, nil
+}
+
+// APIKeyEnv returns the environment variable that holds the API key for a model, if any.
+func (p Provider) APIKeyEnv(settings EndpointSettings) string {
+	keyEnv := strings.TrimSpace(settings.ModelAPIKeyEnv)
+	if keyEnv == "" && p.InheritsCommonAPI {
+		keyEnv = strings.TrimSpace(settings.CommonAPIKeyEnv)
+	}
+	if keyEnv == "" {
+		keyEnv = p.DefaultAPIKeyEnv
+	}
+	return keyEnv
+}
+
+// ProviderRegistry maps models[].provider names to provider definitions.
+type ProviderRegistry struct{ providers map[string]Provider }
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{providers: map[string]Provider{}}
+}
+
+// DefaultProviders returns a registry with the built-in providers.
+func DefaultProviders() *ProviderRegistry {
+	registry := NewProviderRegistry()
+	registry.Register(ProviderOpenAI, Provider{
+		DefaultEndpoint:   openAIDefaultEndpoint,
+		DefaultAPIKeyEnv:  openAIDefaultAPIKeyEnv,
+		RequiresAPIKey:    true,
+		InheritsCommonAPI: true,
+		NewClient:         newOpenAIClient,
+	})
+	registry.Register(ProviderOpenAICompatible, Provider{
+		InheritsCommonAPI: true,
+		NewClient:         newOpenAIClient,
+	})
+	registry.Register(ProviderOllama, Provider{
+		DefaultEndpoint: ollamaDefaultEndpoint,
+		NewClient:       newOpenAIClient,
+	})
+	registry.Register(ProviderAnthropic, Provider{
+		DefaultEndpoint:  anthropicDefaultEndpoint,
+		DefaultAPIKeyEnv: anthropicDefaultKeyEnv,
+		RequiresAPIKey:   true,
+		NewClient: func(endpoint Endpoint) ChatCompleter {
+			return AnthropicClient{HTTPBaseURL: endpoint.BaseURL, APIKey: endpoint.APIKey}
+		},
+	})
+	return registry
+}
+
+func (r *ProviderRegistry) Register(name string, provider Provider) {
+	r.providers[strings.ToLower(name)] = provider
+}
+
+// Lookup finds a provider by name; an empty name means openai for backwards compatibility.
+func (r *ProviderRegistry) Lookup(name string) (Provider, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if normalized == "" {
+		normalized = ProviderOpenAI
+	}
+	provider, ok := r.providers[normalized]
+	return provider, ok
+}
+
+func (r *ProviderRegistry) Names() []string {
+	out := make([]string, 0, len(r.providers))
+	for k := range r.providers {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func newOpenAIClient(endpoint Endpoint) ChatCompleter {
+	return Client{HTTPBaseURL: endpoint.BaseURL, APIKey: endpoint.APIKey}
+}
@@ -0,0 +1,140 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/llm"
+)
+
+func TestProviderResolve(t *testing.T) {
+	environment := map[string]string{
+		"OPENAI_API_KEY":    "openai-key",
+		"ANTHROPIC_API_KEY": "anthropic-key",
+		"CUSTOM_KEY":        "custom-key",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := environment[name]
+		return value, ok
+	}
+	common := llm.EndpointSettings{CommonEndpoint: "https://common.test/v1", CommonAPIKeyEnv: "OPENAI_API_KEY"}
+
+	testCases := []struct {
+		name             string
+		provider         string
+		settings         llm.EndpointSettings
+		expectedEndpoint llm.Endpoint
+		expectedError    string
+	}{
+		{
+			name:             "openai inherits common api",
+			provider:         "openai",
+			settings:         common,
+			expectedEndpoint: llm.Endpoint{BaseURL: "https://common.test/v1", APIKey: "openai-key"},
+		},
+		{
+			name:             "blank provider means openai",
+			provider:         "",
+			settings:         llm.EndpointSettings{},
+			expectedEndpoint: llm.Endpoint{BaseURL: "https://api.openai.com/v1", APIKey: "openai-key"},
+		},
+		{
+			name:             "model endpoint and key env override common",
+			provider:         "openai-compatible",
+			settings:         llm.EndpointSettings{ModelEndpoint: "http://gateway.test/v1/", ModelAPIKeyEnv: "CUSTOM_KEY", CommonEndpoint: common.CommonEndpoint, CommonAPIKeyEnv: common.CommonAPIKeyEnv},
+			expectedEndpoint: llm.Endpoint{BaseURL: "http://gateway.test/v1", APIKey: "custom-key"},
+		},
+		{
+			name:             "ollama ignores common api and needs no key",
+			provider:         "ollama",
+			settings:         common,
+			expectedEndpoint: llm.Endpoint{BaseURL: "http://localhost:11434/v1"},
+		},
+		{
+			name:             "anthropic uses its own defaults",
+			provider:         "Anthropic",
+			settings:         common,
+			expectedEndpoint: llm.Endpoint{BaseURL: "https://api.anthropic.com/v1", APIKey: "anthropic-key"},
+		},
+		{
+			name:          "missing required key",
+			provider:      "openai",
+			settings:      llm.EndpointSettings{ModelAPIKeyEnv: "UNSET_KEY"},
+			expectedError: "UNSET_KEY",
+		},
+	}
+
+	registry := llm.DefaultProviders()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			provider, ok := registry.Lookup(testCase.provider)
+			if !ok {
+				t.Fatalf("provider %q not registered", testCase.provider)
+			}
+			endpoint, err := provider.Resolve(testCase.settings, lookup)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if endpoint != testCase.expectedEndpoint {
+				t.Fatalf("expected %+v, got %+v", testCase.expectedEndpoint, endpoint)
+			}
+		})
+	}
+}
+
+func TestAnthropicClient_TranslatesMessages(t *testing.T) {
+	var received struct {
+		System    string `json:"system"`
+		MaxTokens int    `json:"max_tokens"`
+		Messages  []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" || r.Header.Get("x-api-key") != "secret" {
+			t.Errorf("unexpected request %s key=%q", r.URL.Path, r.Header.Get("x-api-key"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"hello"}],"usage":{"input_tokens":12,"output_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	client := llm.AnthropicClient{HTTPBaseURL: server.URL, APIKey: "secret"}
+	out, err := client.CreateChatCompletion(context.Background(), llm.ChatCompletionRequest{
+		Model: "claude",
+		Messages: []llm.ChatMessage{
+			{Role: "system", Content: "be brief"},
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "bad"},
+			{Role: "user", Content: "again"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+	if out.Content != "hello" {
+		t.Fatalf("expected hello, got %q", out.Content)
+	}
+	if out.Usage.PromptTokens != 12 || out.Usage.CompletionTokens != 3 || out.Usage.TotalTokens != 15 {
+		t.Fatalf("unexpected usage %+v", out.Usage)
+	}
+	if received.System != "be brief" || len(received.Messages) != 3 || received.Messages[1].Role != "assistant" {
+		t.Fatalf("unexpected translated payload: %+v", received)
+	}
+	if received.MaxTokens <= 0 {
+		t.Fatalf("max_tokens must always be sent, got %d", received.MaxTokens)
+	}
+}
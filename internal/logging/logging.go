@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	formatJSON    = "json"
+	formatText    = "text"
+	formatConsole = "console"
+	defaultLevel  = "info"
+)
+
+// New builds a logger from common.logging settings.
+// Level is one of debug|info|warn|error (default info); format is text|console or json (default text).
+func New(level string, format string, sink io.Writer) (*zap.Logger, error) {
+	parsedLevel, levelErr := zapcore.ParseLevel(coalesce(level, defaultLevel))
+	if levelErr != nil {
+		return nil, fmt.Errorf("logging.level: %w", levelErr)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", formatText, formatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	case formatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("logging.format: unknown format %q (use text or json)", format)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(sink), parsedLevel)
+	return zap.New(core), nil
+}
+
+func coalesce(value, fallback string) string {
+	if strings.TrimSpace(value) != "" {
+		return strings.TrimSpace(value)
+	}
+	return fallback
+}
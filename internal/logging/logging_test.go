@@ -0,0 +1,76 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/temirov/llm-tasks/internal/logging"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         string
+		format        string
+		expectedError string
+		check         func(t *testing.T, output string)
+	}{
+		{
+			name:   "json format emits structured fields",
+			level:  "info",
+			format: "json",
+			check: func(t *testing.T, output string) {
+				var event map[string]any
+				if err := json.Unmarshal([]byte(output), &event); err != nil {
+					t.Fatalf("expected JSON line, got %q: %v", output, err)
+				}
+				if event["msg"] != "hello" || event["files"] != float64(3) {
+					t.Fatalf("unexpected event %v", event)
+				}
+			},
+		},
+		{
+			name:   "text format is human readable",
+			format: "text",
+			check: func(t *testing.T, output string) {
+				if !strings.Contains(output, "INFO") || !strings.Contains(output, `"files": 3`) {
+					t.Fatalf("unexpected console output %q", output)
+				}
+			},
+		},
+		{
+			name:   "level filters lower events",
+			level:  "warn",
+			format: "json",
+			check: func(t *testing.T, output string) {
+				if output != "" {
+					t.Fatalf("expected info event to be filtered, got %q", output)
+				}
+			},
+		},
+		{name: "unknown level", level: "loud", expectedError: "logging.level"},
+		{name: "unknown format", format: "xml", expectedError: "logging.format"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			logger, err := logging.New(testCase.level, testCase.format, &buffer)
+			if testCase.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", testCase.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			logger.Info("hello", zap.Int("files", 3))
+			testCase.check(t, strings.TrimSpace(buffer.String()))
+		})
+	}
+}
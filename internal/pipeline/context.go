@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type (
+	loggerContextKey struct{}
+	dryRunContextKey struct{}
+)
+
+// WithLogger attaches a logger to ctx so pipeline stages can emit structured events.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger attached by Runner, or a no-op logger.
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*zap.Logger); ok && logger != nil {
+		return logger
+	}
+	return zap.NewNop()
+}
+
+// WithDryRun marks ctx so Apply reports its plan instead of changing anything.
+func WithDryRun(ctx context.Context, dryRun bool) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, dryRun)
+}
+
+// DryRunFromContext reports whether the run was started with RunOptions.DryRun.
+func DryRunFromContext(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunContextKey{}).(bool)
+	return dryRun
+}
@@ -10,20 +10,63 @@ type Pipeline interface {
 	Apply(ctx context.Context, verified VerifiedOutput) (ApplyReport, error)
 }
 
+// Batcher is implemented by pipelines whose gathered input can be classified in independent chunks.
+// Runner prompts, verifies and refines each batch separately (up to Concurrency at a time) and
+// passes the accepted outputs, in batch order, to Merge before Apply.
+type Batcher interface {
+	Split(ctx context.Context, gathered GatherOutput) (Batches, error)
+	Merge(ctx context.Context, verified []VerifiedOutput) (VerifiedOutput, error)
+}
+
+type Batches struct {
+	Items       []GatherOutput
+	Concurrency int
+}
+
 type GatherOutput any
 type VerifiedOutput any
 
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single conversation turn that follows the initial user prompt.
+type Message struct {
+	Role    string
+	Content string
+}
+
 type LLMRequest struct {
 	SystemPrompt string
 	UserPrompt   string
-	JSONSchema   []byte
-	MaxTokens    int
-	Temperature  float64
-	Model        string
+	// Messages holds follow-up turns (e.g. rejected replies and refine requests) sent after UserPrompt.
+	Messages    []Message
+	JSONSchema  []byte
+	MaxTokens   int
+	Temperature float64
+	Model       string
 }
 
 type LLMResponse struct {
 	RawText string
+	Usage   Usage
+}
+
+// Usage is the token accounting reported by the provider for one or more calls.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Add returns the sum of two usage records.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
 }
 
 type RefineRequest struct {
@@ -35,4 +78,10 @@ type ApplyReport struct {
 	DryRun     bool
 	Summary    string
 	NumActions int
+	// Usage is filled in by Runner with the tokens spent across all attempts.
+	Usage Usage
+	// FailedBatches counts batches that were dropped after exhausting their attempts.
+	FailedBatches int
+	// Skipped counts items the task deliberately left alone (e.g. unconfirmed low-confidence moves).
+	Skipped int
 }
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 type LLMClient interface {
@@ -22,54 +26,226 @@ type RunOptions struct {
 type Runner struct {
 	Client  LLMClient
 	Options RunOptions
+	// Logger receives structured pipeline events; nil disables logging.
+	Logger *zap.Logger
 }
 
 func (r Runner) Run(ctx context.Context, p Pipeline) (ApplyReport, error) {
+	logger := r.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	logger = logger.With(zap.String("pipeline", p.Name()))
+	ctx = WithLogger(ctx, logger)
+	if r.Options.DryRun {
+		ctx = WithDryRun(ctx, true)
+	}
+
 	gathered, gatherErr := p.Gather(ctx)
 	if gatherErr != nil {
 		return ApplyReport{}, fmt.Errorf("gather: %w", gatherErr)
 	}
+	logger.Info("gathered", zap.Int("items", gatheredSize(gathered)))
 
+	var (
+		verified      VerifiedOutput
+		usage         Usage
+		failedBatches int
+	)
+	if batcher, ok := p.(Batcher); ok {
+		var batchErr error
+		verified, usage, failedBatches, batchErr = r.runBatches(ctx, p, batcher, gathered, logger)
+		if batchErr != nil {
+			return ApplyReport{Usage: usage}, batchErr
+		}
+	} else {
+		var convErr error
+		verified, usage, convErr = r.converse(ctx, p, gathered, logger)
+		if convErr != nil {
+			return ApplyReport{Usage: usage}, convErr
+		}
+	}
+
+	report, applyErr := p.Apply(ctx, verified)
+	// the calls are billed whether or not Apply succeeds
+	report.Usage = usage
+	report.FailedBatches = failedBatches
+	if applyErr != nil {
+		return report, applyErr
+	}
+	logger.Info("applied",
+		zap.String("summary", report.Summary),
+		zap.Int("actions", report.NumActions),
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("skipped", report.Skipped),
+		zap.Int("failed_batches", failedBatches),
+		zap.Int("total_tokens", usage.TotalTokens),
+	)
+	return report, nil
+}
+
+// converse runs the prompt → chat → verify → refine loop for one gathered input.
+// A Verify that rejects with a refine may also return the part of the output it accepted;
+// the latest such partial output is returned instead of an error once attempts run out.
+func (r Runner) converse(ctx context.Context, p Pipeline, gathered GatherOutput, logger *zap.Logger) (VerifiedOutput, Usage, error) {
 	var (
 		lastResponse LLMResponse
-		verified     VerifiedOutput
-		accepted     bool
+		history      []Message
+		usage        Usage
+		partial      VerifiedOutput
 	)
 	for attempt := 1; attempt <= max(1, r.Options.MaxAttempts); attempt++ {
 		req, reqErr := p.Prompt(ctx, gathered)
 		if reqErr != nil {
-			return ApplyReport{}, fmt.Errorf("prompt: %w", reqErr)
+			return nil, usage, fmt.Errorf("prompt: %w", reqErr)
 		}
+		req.Messages = append(req.Messages, history...)
 		attemptCtx, cancel := context.WithTimeout(ctx, r.Options.Timeout)
+		started := time.Now()
 		resp, chatErr := r.Client.Chat(attemptCtx, req)
 		cancel()
 		if chatErr != nil {
-			return ApplyReport{}, fmt.Errorf("llm chat: %w", chatErr)
+			logger.Error("llm attempt failed", zap.Int("attempt", attempt), zap.Duration("latency", time.Since(started)), zap.Error(chatErr))
+			return nil, usage, fmt.Errorf("llm chat: %w", chatErr)
 		}
 		lastResponse = resp
+		usage = usage.Add(resp.Usage)
+		logger.Info("llm attempt",
+			zap.Int("attempt", attempt),
+			zap.Int("prompt_chars", promptLength(req)),
+			zap.Int("response_chars", len(resp.RawText)),
+			zap.Duration("latency", time.Since(started)),
+			zap.Int("total_tokens", resp.Usage.TotalTokens),
+		)
 
-		ok, out, refine, verErr := p.Verify(ctx, gathered, resp)
-		if verErr != nil {
-			return ApplyReport{}, fmt.Errorf("verify: %w", verErr)
-		}
-		if ok {
-			accepted = true
-			verified = out
-			break
+		var refine *RefineRequest
+		if len(req.JSONSchema) > 0 {
+			refine = schemaRefine(req.JSONSchema, resp)
 		}
 		if refine == nil {
-			return ApplyReport{}, errors.New("verify rejected result and no refine request provided")
+			ok, out, verifyRefine, verErr := p.Verify(ctx, gathered, resp)
+			if verErr != nil {
+				return nil, usage, fmt.Errorf("verify: %w", verErr)
+			}
+			if ok {
+				return out, usage, nil
+			}
+			if verifyRefine == nil {
+				return nil, usage, errors.New("verify rejected result and no refine request provided")
+			}
+			if out != nil {
+				partial = out
+			}
+			refine = verifyRefine
 		}
-		// mutate request by appending delta; tasks may encode their own logic if needed
-		req.UserPrompt = req.UserPrompt + "\n\nREFINE:\n" + refine.UserPromptDelta
+		logger.Warn("refine requested", zap.Int("attempt", attempt), zap.String("reason", refine.Reason))
+		// continue the conversation: the model sees its rejected reply followed by the refine request
+		history = append(history,
+			Message{Role: RoleAssistant, Content: resp.RawText},
+			Message{Role: RoleUser, Content: refine.UserPromptDelta},
+		)
 	}
+	if partial != nil {
+		logger.Warn("attempts exhausted, keeping partially accepted output")
+		return partial, usage, nil
+	}
+	return nil, usage, fmt.Errorf("exhausted attempts without acceptance (last response: %s)", truncate(lastResponse.RawText, 280))
+}
 
-	if !accepted {
-		return ApplyReport{}, fmt.Errorf("exhausted attempts without acceptance (last response: %s)", truncate(lastResponse.RawText, 280))
+type batchResult struct {
+	verified VerifiedOutput
+	usage    Usage
+	err      error
+}
+
+// runBatches converses over every batch with bounded parallelism and merges the accepted ones.
+// A failed batch is logged and skipped; the run only fails when no batch was accepted.
+func (r Runner) runBatches(ctx context.Context, p Pipeline, batcher Batcher, gathered GatherOutput, logger *zap.Logger) (VerifiedOutput, Usage, int, error) {
+	batches, splitErr := batcher.Split(ctx, gathered)
+	if splitErr != nil {
+		return nil, Usage{}, 0, fmt.Errorf("split: %w", splitErr)
 	}
+	logger.Info("batched", zap.Int("batches", len(batches.Items)), zap.Int("concurrency", max(1, batches.Concurrency)))
 
-	report, applyErr := p.Apply(ctx, verified)
-	return report, applyErr
+	results := make([]batchResult, len(batches.Items))
+	semaphore := make(chan struct{}, max(1, batches.Concurrency))
+	var wg sync.WaitGroup
+	for index, batch := range batches.Items {
+		wg.Add(1)
+		go func(index int, batch GatherOutput) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				results[index] = batchResult{err: ctx.Err()}
+				return
+			}
+			defer func() { <-semaphore }()
+			batchLogger := logger.With(zap.Int("batch", index+1), zap.Int("batch_items", gatheredSize(batch)))
+			verified, usage, err := r.converse(WithLogger(ctx, batchLogger), p, batch, batchLogger)
+			results[index] = batchResult{verified: verified, usage: usage, err: err}
+		}(index, batch)
+	}
+	wg.Wait()
+
+	var (
+		accepted []VerifiedOutput
+		usage    Usage
+		failures []error
+	)
+	for index, result := range results {
+		usage = usage.Add(result.usage)
+		if result.err != nil {
+			logger.Error("batch failed", zap.Int("batch", index+1), zap.Error(result.err))
+			failures = append(failures, fmt.Errorf("batch %d: %w", index+1, result.err))
+			continue
+		}
+		accepted = append(accepted, result.verified)
+	}
+	if len(batches.Items) > 0 && len(accepted) == 0 {
+		return nil, usage, len(failures), errors.Join(failures...)
+	}
+
+	merged, mergeErr := batcher.Merge(ctx, accepted)
+	if mergeErr != nil {
+		return nil, usage, len(failures), fmt.Errorf("merge: %w", mergeErr)
+	}
+	return merged, usage, len(failures), nil
+}
+
+// schemaRefine validates a structured response locally so schema violations never reach Verify.
+func schemaRefine(schema []byte, resp LLMResponse) *RefineRequest {
+	if err := ValidateJSONSchema(schema, resp.RawText); err != nil {
+		return &RefineRequest{
+			UserPromptDelta: fmt.Sprintf("The previous output did not match the required JSON schema (%v). Re-send strictly valid JSON that satisfies the schema.", err),
+			Reason:          "schema-violation",
+		}
+	}
+	return nil
+}
+
+// gatheredSize reports the number of gathered items for slices, maps and values with a Len method, and 1 otherwise.
+func gatheredSize(gathered GatherOutput) int {
+	if sized, ok := gathered.(interface{ Len() int }); ok {
+		return sized.Len()
+	}
+	value := reflect.ValueOf(gathered)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return value.Len()
+	case reflect.Invalid:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func promptLength(req LLMRequest) int {
+	total := len(req.SystemPrompt) + len(req.UserPrompt)
+	for _, m := range req.Messages {
+		total += len(m.Content)
+	}
+	return total
 }
 
 func truncate(s string, n int) string {
@@ -3,30 +3,41 @@ package pipeline_test
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
 	"github.com/temirov/llm-tasks/internal/pipeline"
 )
 
 type fakeClient struct {
 	responses []string
 	call      int
+	requests  []pipeline.LLMRequest
+	usage     pipeline.Usage
 }
 
 func (f *fakeClient) Chat(ctx context.Context, req pipeline.LLMRequest) (pipeline.LLMResponse, error) {
+	f.requests = append(f.requests, req)
 	if f.call >= len(f.responses) {
 		return pipeline.LLMResponse{}, errors.New("no more responses")
 	}
 	r := f.responses[f.call]
 	f.call++
-	return pipeline.LLMResponse{RawText: r}, nil
+	return pipeline.LLMResponse{RawText: r, Usage: f.usage}, nil
 }
 
 type fakePipeline struct {
 	gathered any
+	schema   []byte
 	verify   func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error)
 	applied  bool
+	output   pipeline.VerifiedOutput
+	applyErr error
 }
 
 func (p *fakePipeline) Name() string { return "fake" }
@@ -35,13 +46,17 @@ func (p *fakePipeline) Gather(ctx context.Context) (pipeline.GatherOutput, error
 	return p.gathered, nil
 }
 func (p *fakePipeline) Prompt(ctx context.Context, g pipeline.GatherOutput) (pipeline.LLMRequest, error) {
-	return pipeline.LLMRequest{UserPrompt: "hi"}, nil
+	return pipeline.LLMRequest{UserPrompt: "hi", JSONSchema: p.schema}, nil
 }
 func (p *fakePipeline) Verify(ctx context.Context, g pipeline.GatherOutput, r pipeline.LLMResponse) (bool, pipeline.VerifiedOutput, *pipeline.RefineRequest, error) {
 	return p.verify(g, r)
 }
 func (p *fakePipeline) Apply(ctx context.Context, v pipeline.VerifiedOutput) (pipeline.ApplyReport, error) {
 	p.applied = true
+	p.output = v
+	if p.applyErr != nil {
+		return pipeline.ApplyReport{}, p.applyErr
+	}
 	return pipeline.ApplyReport{DryRun: false, Summary: "ok", NumActions: 1}, nil
 }
 
@@ -57,18 +72,42 @@ func TestRunner_RefineFlow(t *testing.T) {
 			return false, nil, nil, errors.New("unexpected")
 		},
 	}
-	client := &fakeClient{responses: []string{"bad", "good"}}
+	client := &fakeClient{responses: []string{"bad", "good"}, usage: pipeline.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}
 	r := pipeline.Runner{
 		Client:  client,
 		Options: pipeline.RunOptions{MaxAttempts: 3, Timeout: 2 * time.Second},
 	}
-	_, err := r.Run(context.Background(), fp)
+	report, err := r.Run(context.Background(), fp)
 	if err != nil {
 		t.Fatalf("Run: %v", err)
 	}
 	if !fp.applied {
 		t.Fatalf("expected Apply to be called")
 	}
+	wantUsage := pipeline.Usage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30}
+	if report.Usage != wantUsage {
+		t.Fatalf("expected usage summed across attempts %+v, got %+v", wantUsage, report.Usage)
+	}
+}
+
+func TestRunner_ApplyErrorKeepsUsage(t *testing.T) {
+	fp := &fakePipeline{
+		verify: func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+			return true, "verified", nil, nil
+		},
+		applyErr: errors.New("disk full"),
+	}
+	r := pipeline.Runner{
+		Client:  &fakeClient{responses: []string{"good"}, usage: pipeline.Usage{TotalTokens: 15}},
+		Options: pipeline.RunOptions{MaxAttempts: 1, Timeout: time.Second},
+	}
+	report, err := r.Run(context.Background(), fp)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the apply error, got %v", err)
+	}
+	if report.Usage.TotalTokens != 15 {
+		t.Fatalf("expected the billed usage in the report, got %+v", report.Usage)
+	}
 }
 
 func TestRunner_ExhaustAttempts(t *testing.T) {
@@ -87,3 +126,220 @@ func TestRunner_ExhaustAttempts(t *testing.T) {
 		t.Fatalf("expected error after exhausting attempts")
 	}
 }
+
+func TestRunner_ExhaustAttemptsKeepsPartialOutput(t *testing.T) {
+	fp := &fakePipeline{
+		verify: func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+			if r.RawText == "unparseable" {
+				return false, nil, &pipeline.RefineRequest{UserPromptDelta: "json please"}, nil
+			}
+			return false, "accepted part of " + r.RawText, &pipeline.RefineRequest{UserPromptDelta: "fix the rest"}, nil
+		},
+	}
+	client := &fakeClient{responses: []string{"partial1", "partial2", "unparseable"}}
+	r := pipeline.Runner{
+		Client:  client,
+		Options: pipeline.RunOptions{MaxAttempts: 3, Timeout: time.Second},
+	}
+	if _, err := r.Run(context.Background(), fp); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if fp.output != "accepted part of partial2" {
+		t.Fatalf("expected the latest partial output to be applied, got %v", fp.output)
+	}
+}
+
+func TestRunner_RefineCarriesConversation(t *testing.T) {
+	fp := &fakePipeline{
+		verify: func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+			if r.RawText == "good" {
+				return true, "verified", nil, nil
+			}
+			return false, nil, &pipeline.RefineRequest{UserPromptDelta: "fix " + r.RawText, Reason: "bad"}, nil
+		},
+	}
+	client := &fakeClient{responses: []string{"bad1", "bad2", "good"}}
+	r := pipeline.Runner{
+		Client:  client,
+		Options: pipeline.RunOptions{MaxAttempts: 3, Timeout: time.Second},
+	}
+	if _, err := r.Run(context.Background(), fp); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(client.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(client.requests))
+	}
+	if len(client.requests[0].Messages) != 0 {
+		t.Fatalf("first attempt should carry no history, got %+v", client.requests[0].Messages)
+	}
+	want := []pipeline.Message{
+		{Role: pipeline.RoleAssistant, Content: "bad1"},
+		{Role: pipeline.RoleUser, Content: "fix bad1"},
+		{Role: pipeline.RoleAssistant, Content: "bad2"},
+		{Role: pipeline.RoleUser, Content: "fix bad2"},
+	}
+	got := client.requests[2]
+	if got.UserPrompt != "hi" {
+		t.Fatalf("original prompt must be kept, got %q", got.UserPrompt)
+	}
+	if len(got.Messages) != len(want) {
+		t.Fatalf("expected %d history messages, got %+v", len(want), got.Messages)
+	}
+	for i := range want {
+		if got.Messages[i] != want[i] {
+			t.Fatalf("message %d: expected %+v, got %+v", i, want[i], got.Messages[i])
+		}
+	}
+}
+
+func TestRunner_SchemaViolationRefinesBeforeVerify(t *testing.T) {
+	var verified []string
+	fp := &fakePipeline{
+		schema: []byte(`{"type":"object","properties":{"ok":{"type":"boolean"}},"required":["ok"],"additionalProperties":false}`),
+		verify: func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+			verified = append(verified, r.RawText)
+			return true, "verified", nil, nil
+		},
+	}
+	client := &fakeClient{responses: []string{`{"ok":"yes"}`, `{"ok":true}`}}
+	r := pipeline.Runner{
+		Client:  client,
+		Options: pipeline.RunOptions{MaxAttempts: 2, Timeout: time.Second},
+	}
+	if _, err := r.Run(context.Background(), fp); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(verified) != 1 || verified[0] != `{"ok":true}` {
+		t.Fatalf("expected only the schema-valid response to reach Verify, got %v", verified)
+	}
+	refine := client.requests[1].Messages[1]
+	if refine.Role != pipeline.RoleUser || !strings.Contains(refine.Content, "$.ok: expected boolean") {
+		t.Fatalf("expected schema refine message, got %+v", refine)
+	}
+}
+
+func TestRunner_EmitsStructuredEvents(t *testing.T) {
+	core, observed := observer.New(zap.DebugLevel)
+	fp := &fakePipeline{
+		verify: func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+			if r.RawText == "good" {
+				return true, "verified", nil, nil
+			}
+			return false, nil, &pipeline.RefineRequest{UserPromptDelta: "fix", Reason: "too-vague"}, nil
+		},
+	}
+	r := pipeline.Runner{
+		Client:  &fakeClient{responses: []string{"bad", "good"}},
+		Options: pipeline.RunOptions{MaxAttempts: 2, Timeout: time.Second},
+		Logger:  zap.New(core),
+	}
+	if _, err := r.Run(context.Background(), fp); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	gathered := observed.FilterMessage("gathered").All()
+	if len(gathered) != 1 || gathered[0].ContextMap()["items"] != int64(3) {
+		t.Fatalf("expected gathered event with 3 items, got %+v", gathered)
+	}
+	if got := observed.FilterMessage("llm attempt").Len(); got != 2 {
+		t.Fatalf("expected 2 attempt events, got %d", got)
+	}
+	refines := observed.FilterMessage("refine requested").All()
+	if len(refines) != 1 || refines[0].ContextMap()["reason"] != "too-vague" {
+		t.Fatalf("expected refine event with reason, got %+v", refines)
+	}
+	if got := observed.FilterMessage("applied").FilterField(zap.String("pipeline", "fake")).Len(); got != 1 {
+		t.Fatalf("expected applied event tagged with pipeline name, got %d", got)
+	}
+}
+
+type batchedPipeline struct {
+	fakePipeline
+	concurrency int
+	merged      []pipeline.VerifiedOutput
+}
+
+func (p *batchedPipeline) Gather(ctx context.Context) (pipeline.GatherOutput, error) {
+	return []string{"a", "b", "bad", "c"}, nil
+}
+func (p *batchedPipeline) Prompt(ctx context.Context, g pipeline.GatherOutput) (pipeline.LLMRequest, error) {
+	return pipeline.LLMRequest{UserPrompt: g.(string)}, nil
+}
+func (p *batchedPipeline) Split(ctx context.Context, g pipeline.GatherOutput) (pipeline.Batches, error) {
+	var items []pipeline.GatherOutput
+	for _, item := range g.([]string) {
+		items = append(items, item)
+	}
+	return pipeline.Batches{Items: items, Concurrency: p.concurrency}, nil
+}
+func (p *batchedPipeline) Merge(ctx context.Context, verified []pipeline.VerifiedOutput) (pipeline.VerifiedOutput, error) {
+	p.merged = verified
+	return verified, nil
+}
+
+// echoClient answers with the prompt and tracks how many calls run at once.
+type echoClient struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *echoClient) Chat(ctx context.Context, req pipeline.LLMRequest) (pipeline.LLMResponse, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return pipeline.LLMResponse{RawText: req.UserPrompt, Usage: pipeline.Usage{TotalTokens: 1}}, nil
+}
+
+func TestRunner_BatchesRunBoundedAndSkipFailures(t *testing.T) {
+	bp := &batchedPipeline{concurrency: 2}
+	bp.verify = func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+		if r.RawText == "bad" {
+			return false, nil, &pipeline.RefineRequest{UserPromptDelta: "again", Reason: "bad"}, nil
+		}
+		return true, r.RawText, nil, nil
+	}
+	client := &echoClient{}
+	r := pipeline.Runner{
+		Client:  client,
+		Options: pipeline.RunOptions{MaxAttempts: 2, Timeout: time.Second},
+	}
+	report, err := r.Run(context.Background(), bp)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.FailedBatches != 1 {
+		t.Fatalf("expected 1 failed batch, got %d", report.FailedBatches)
+	}
+	if len(bp.merged) != 3 || bp.merged[0] != "a" || bp.merged[1] != "b" || bp.merged[2] != "c" {
+		t.Fatalf("expected accepted batches merged in order, got %v", bp.merged)
+	}
+	if report.Usage.TotalTokens != 5 {
+		t.Fatalf("expected usage from every attempt including the failed batch, got %+v", report.Usage)
+	}
+	if client.peak > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, saw %d", client.peak)
+	}
+}
+
+func TestRunner_AllBatchesFailing(t *testing.T) {
+	bp := &batchedPipeline{concurrency: 4}
+	bp.verify = func(g any, r pipeline.LLMResponse) (bool, any, *pipeline.RefineRequest, error) {
+		return false, nil, &pipeline.RefineRequest{UserPromptDelta: "again"}, nil
+	}
+	r := pipeline.Runner{
+		Client:  &echoClient{},
+		Options: pipeline.RunOptions{MaxAttempts: 1, Timeout: time.Second},
+	}
+	if _, err := r.Run(context.Background(), bp); err == nil {
+		t.Fatalf("expected error when every batch fails")
+	}
+	if bp.applied {
+		t.Fatalf("Apply must not run when every batch fails")
+	}
+}
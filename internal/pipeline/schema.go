@@ -0,0 +1,262 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// jsonSchema is the subset of JSON Schema that tasks use to describe structured output.
+// It mirrors what OpenAI strict structured outputs accept: type, properties, required,
+// additionalProperties, items, enum, and simple numeric/string/array bounds.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// compiledSchemas caches parsed schemas by their text; tasks send the same schema on every attempt.
+var compiledSchemas sync.Map
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "null"].
+type schemaTypes []string
+
+func (s *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = schemaTypes{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("schema type must be a string or array of strings: %w", err)
+	}
+	*s = many
+	return nil
+}
+
+// ValidateJSONSchema checks raw JSON text against a JSON Schema document.
+// The returned error names the offending location, e.g. "$.results[2].confidence: expected number".
+func ValidateJSONSchema(schema []byte, raw string) error {
+	root, err := compileSchema(schema)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("invalid JSON: trailing data after value")
+	}
+	return root.validate("$", value)
+}
+
+func compileSchema(schema []byte) (*jsonSchema, error) {
+	if cached, ok := compiledSchemas.Load(string(schema)); ok {
+		return cached.(*jsonSchema), nil
+	}
+	var root jsonSchema
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if err := root.compilePatterns("$"); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	compiledSchemas.Store(string(schema), &root)
+	return &root, nil
+}
+
+// compilePatterns compiles every "pattern" once so validation only matches.
+func (s *jsonSchema) compilePatterns(path string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: bad schema pattern: %w", path, err)
+		}
+		s.pattern = re
+	}
+	for name, child := range s.Properties {
+		if err := child.compilePatterns(path + "." + name); err != nil {
+			return err
+		}
+	}
+	return s.Items.compilePatterns(path + "[]")
+}
+
+func (s *jsonSchema) validate(path string, value any) error {
+	if s == nil {
+		return nil
+	}
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), jsonTypeName(value))
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		return fmt.Errorf("%s: value %v is not one of the allowed values", path, value)
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return s.validateObject(path, v)
+	case []any:
+		return s.validateArray(path, v)
+	case json.Number:
+		return s.validateNumber(path, v)
+	case string:
+		return s.validateString(path, v)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(path string, object map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+	for name, child := range object {
+		childSchema, known := s.Properties[name]
+		if !known {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+			continue
+		}
+		if err := childSchema.validate(path+"."+name, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(path string, array []any) error {
+	if s.MinItems != nil && len(array) < *s.MinItems {
+		return fmt.Errorf("%s: expected at least %d items, got %d", path, *s.MinItems, len(array))
+	}
+	if s.MaxItems != nil && len(array) > *s.MaxItems {
+		return fmt.Errorf("%s: expected at most %d items, got %d", path, *s.MaxItems, len(array))
+	}
+	for i, item := range array {
+		if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateNumber(path string, number json.Number) error {
+	f, err := number.Float64()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		return fmt.Errorf("%s: %v is below minimum %v", path, f, *s.Minimum)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		return fmt.Errorf("%s: %v is above maximum %v", path, f, *s.Maximum)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(path string, str string) error {
+	length := len([]rune(str))
+	if s.MinLength != nil && length < *s.MinLength {
+		return fmt.Errorf("%s: expected at least %d characters", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Errorf("%s: expected at most %d characters", path, *s.MaxLength)
+	}
+	if s.pattern != nil {
+		if !s.pattern.MatchString(str) {
+			return fmt.Errorf("%s: %q does not match pattern %s", path, str, s.Pattern)
+		}
+	}
+	return nil
+}
+
+func (t schemaTypes) matches(value any) bool {
+	for _, name := range t {
+		switch name {
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "number":
+			if _, ok := value.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(json.Number); ok {
+				if _, err := n.Int64(); err == nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func enumContains(enum []any, value any) bool {
+	encodedValue, _ := json.Marshal(value)
+	for _, allowed := range enum {
+		encodedAllowed, _ := json.Marshal(allowed)
+		if bytes.Equal(encodedAllowed, encodedValue) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package pipeline_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "results": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "score": {"type": "number", "minimum": 0, "maximum": 1},
+          "kind": {"type": "string", "enum": ["a", "b"]},
+          "code": {"type": "string", "pattern": "^[a-z]+$"}
+        },
+        "required": ["name", "score"],
+        "additionalProperties": false
+      }
+    }
+  },
+  "required": ["results"],
+  "additionalProperties": false
+}`
+
+func TestValidateJSONSchema(t *testing.T) {
+	testCases := []struct {
+		name          string
+		raw           string
+		expectedError string
+	}{
+		{name: "valid", raw: `{"results":[{"name":"x","score":0.5,"kind":"a"}]}`},
+		{name: "empty array", raw: `{"results":[]}`},
+		{name: "not json", raw: `results: []`, expectedError: "invalid JSON"},
+		{name: "bare array", raw: `[]`, expectedError: "$: expected object, got array"},
+		{name: "missing required", raw: `{"results":[{"name":"x"}]}`, expectedError: `$.results[0]: missing required property "score"`},
+		{name: "wrong type", raw: `{"results":[{"name":"x","score":"high"}]}`, expectedError: "$.results[0].score: expected number, got string"},
+		{name: "out of range", raw: `{"results":[{"name":"x","score":1.5}]}`, expectedError: "above maximum"},
+		{name: "unexpected property", raw: `{"results":[],"extra":true}`, expectedError: `unexpected property "extra"`},
+		{name: "enum violation", raw: `{"results":[{"name":"x","score":0,"kind":"c"}]}`, expectedError: "$.results[0].kind"},
+		{name: "pattern match", raw: `{"results":[{"name":"x","score":0,"code":"abc"}]}`},
+		{name: "pattern violation", raw: `{"results":[{"name":"x","score":0,"code":"ABC"}]}`, expectedError: `$.results[0].code: "ABC" does not match pattern ^[a-z]+$`},
+		{name: "trailing data", raw: `{"results":[]} {}`, expectedError: "trailing data"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := pipeline.ValidateJSONSchema([]byte(testSchema), testCase.raw)
+			if testCase.expectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateJSONSchemaRejectsBadPattern(t *testing.T) {
+	schema := `{"type":"object","properties":{"code":{"type":"string","pattern":"["}}}`
+	err := pipeline.ValidateJSONSchema([]byte(schema), `{}`)
+	if err == nil || !strings.Contains(err.Error(), "$.code: bad schema pattern") {
+		t.Fatalf("expected bad pattern error, got %v", err)
+	}
+}
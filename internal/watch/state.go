@@ -0,0 +1,125 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+// State remembers which files a watcher already handed to its handler, so a restart or a
+// file left in place (skipped, low confidence) is not classified twice.
+type State struct {
+	Files map[string]StateEntry `json:"files"`
+}
+
+// StateEntry identifies the processed version of a file by size and mtime; a new download
+// reusing the same name is treated as a new file.
+type StateEntry struct {
+	SizeBytes   int64     `json:"size_bytes"`
+	ModTime     time.Time `json:"mod_time"`
+	ProcessedAt time.Time `json:"processed_at,omitzero"`
+	// Failures counts failed batches for this version of the file; RetryAt is when it may be
+	// handed to the handler again.
+	Failures int       `json:"failures,omitempty"`
+	RetryAt  time.Time `json:"retry_at,omitzero"`
+}
+
+// LoadState reads the state file; a missing file is an empty state.
+func LoadState(fs fsops.Ops, path string) (*State, error) {
+	state := &State{Files: map[string]StateEntry{}}
+	if !fs.FileExists(path) {
+		return state, nil
+	}
+	data, err := fs.FS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse watch state %s: %w", path, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]StateEntry{}
+	}
+	return state, nil
+}
+
+func (s *State) Save(fs fsops.Ops, path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fs.EnsureDir(path); err != nil {
+		return err
+	}
+	return fs.FS.WriteFile(path, data, 0o644)
+}
+
+// Processed reports whether this exact version of the file was already handled.
+func (s *State) Processed(info fsops.FileInfo) bool {
+	entry, ok := s.entry(info)
+	return ok && !entry.ProcessedAt.IsZero()
+}
+
+// Deferred reports whether this version of the file failed before and must not be retried
+// yet: its backoff has not elapsed, or it failed maxRetries times and waits for a new version.
+func (s *State) Deferred(info fsops.FileInfo, now time.Time, maxRetries int) bool {
+	entry, ok := s.entry(info)
+	if !ok || entry.Failures == 0 {
+		return false
+	}
+	return entry.Failures >= maxRetries || now.Before(entry.RetryAt)
+}
+
+// MarkFailed counts a failed batch for each file and schedules its retry after backoff,
+// doubling with every failure up to maxBackoff. It returns the files that reached maxRetries.
+func (s *State) MarkFailed(infos []fsops.FileInfo, at time.Time, backoff, maxBackoff time.Duration, maxRetries int) []string {
+	var exhausted []string
+	for _, info := range infos {
+		entry, _ := s.entry(info)
+		failures := entry.Failures + 1
+		delay := backoff
+		for step := 1; step < failures && delay < maxBackoff; step++ {
+			delay *= 2
+		}
+		s.Files[info.AbsolutePath] = StateEntry{
+			SizeBytes: info.SizeBytes,
+			ModTime:   info.ModTime,
+			Failures:  failures,
+			RetryAt:   at.Add(min(delay, maxBackoff)).UTC(),
+		}
+		if failures >= maxRetries {
+			exhausted = append(exhausted, info.AbsolutePath)
+		}
+	}
+	return exhausted
+}
+
+// entry returns the state of this exact version of the file.
+func (s *State) entry(info fsops.FileInfo) (StateEntry, bool) {
+	entry, ok := s.Files[info.AbsolutePath]
+	if !ok || entry.SizeBytes != info.SizeBytes || !entry.ModTime.Equal(info.ModTime) {
+		return StateEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *State) Mark(infos []fsops.FileInfo, at time.Time) {
+	for _, info := range infos {
+		s.Files[info.AbsolutePath] = StateEntry{SizeBytes: info.SizeBytes, ModTime: info.ModTime, ProcessedAt: at.UTC()}
+	}
+}
+
+// Prune forgets files that are no longer in the watched directory, typically because they were sorted.
+func (s *State) Prune(present []fsops.FileInfo) {
+	keep := make(map[string]bool, len(present))
+	for _, info := range present {
+		keep[info.AbsolutePath] = true
+	}
+	for path := range s.Files {
+		if !keep[path] {
+			delete(s.Files, path)
+		}
+	}
+}
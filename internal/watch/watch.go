@@ -0,0 +1,252 @@
+// Package watch polls a directory and hands newly arrived files to a handler once their
+// size has settled, grouped into time-windowed batches.
+package watch
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+const (
+	DefaultInterval   = 2 * time.Second
+	DefaultSettle     = 5 * time.Second
+	DefaultWindow     = 30 * time.Second
+	DefaultBackoff    = time.Minute
+	DefaultMaxRetries = 5
+
+	maxBackoff = time.Hour
+)
+
+// partialDownloadExtensions are in-progress files written by browsers and download managers.
+var partialDownloadExtensions = []string{".crdownload", ".part", ".partial", ".download", ".tmp"}
+
+type Options struct {
+	// Interval between directory scans.
+	Interval time.Duration
+	// Settle is how long a file's size and mtime must stay unchanged before it counts as arrived.
+	Settle time.Duration
+	// Window collects arrivals after the first one so a burst of downloads becomes one batch.
+	Window time.Duration
+	// Backoff is the wait before a file from a failed batch is retried; it doubles with every
+	// failure, up to an hour.
+	Backoff time.Duration
+	// MaxRetries is how many failed batches a file may take part in before the watcher leaves
+	// it alone until it changes.
+	MaxRetries int
+}
+
+// Handler processes one batch of settled files and returns the paths it could not process.
+// Those paths, or the whole batch when it returns an error, are retried with backoff; the
+// rest are marked processed in the state file.
+type Handler func(ctx context.Context, paths []string) (failed []string, err error)
+
+type Watcher struct {
+	FS        fsops.Ops
+	Root      string
+	StatePath string
+	Options   Options
+	// Logger receives watch events; nil disables logging.
+	Logger *zap.Logger
+
+	now func() time.Time
+}
+
+// Run scans Root until ctx is cancelled. Handler errors are logged and the failed files are
+// retried with exponential backoff, up to MaxRetries times; only state file and inventory
+// errors stop the watcher.
+func (w *Watcher) Run(ctx context.Context, handle Handler) error {
+	logger := w.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	now := w.now
+	if now == nil {
+		now = time.Now
+	}
+	state, err := LoadState(w.FS, w.StatePath)
+	if err != nil {
+		return err
+	}
+	options := w.Options.withDefaults()
+	arrivals := newTracker(options.Settle, options.Window)
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+	logger.Info("watching", zap.String("root", w.Root), zap.Duration("settle", options.Settle), zap.Duration("window", options.Window))
+
+	for {
+		infos, err := w.FS.Inventory(w.Root)
+		if err != nil {
+			return err
+		}
+		infos = withoutPartialDownloads(infos)
+		scannedAt := now()
+		skip := func(info fsops.FileInfo) bool {
+			return state.Processed(info) || state.Deferred(info, scannedAt, options.MaxRetries)
+		}
+		if batch := arrivals.observe(scannedAt, infos, skip); len(batch) > 0 {
+			paths := make([]string, 0, len(batch))
+			for _, info := range batch {
+				paths = append(paths, info.AbsolutePath)
+			}
+			logger.Info("batch ready", zap.Int("files", len(paths)))
+			done, retry := batch, []fsops.FileInfo(nil)
+			failed, handleErr := handle(ctx, paths)
+			if handleErr != nil {
+				logger.Error("batch failed", zap.Int("files", len(paths)), zap.Error(handleErr))
+				done, retry = nil, batch
+			} else if len(failed) > 0 {
+				logger.Warn("files left unprocessed", zap.Strings("paths", failed))
+				done, retry = partition(batch, failed)
+			}
+			state.Mark(done, now())
+			if exhausted := state.MarkFailed(retry, now(), options.Backoff, maxBackoff, options.MaxRetries); len(exhausted) > 0 {
+				logger.Warn("giving up until the files change", zap.Strings("paths", exhausted), zap.Int("max_retries", options.MaxRetries))
+			}
+			state.Prune(infos)
+			if err := state.Save(w.FS, w.StatePath); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o Options) withDefaults() Options {
+	if o.Interval <= 0 {
+		o.Interval = DefaultInterval
+	}
+	if o.Settle <= 0 {
+		o.Settle = DefaultSettle
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultWindow
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultBackoff
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	return o
+}
+
+// partition splits batch into the files the handler processed and the failed ones.
+func partition(batch []fsops.FileInfo, failed []string) ([]fsops.FileInfo, []fsops.FileInfo) {
+	failedPaths := make(map[string]bool, len(failed))
+	for _, path := range failed {
+		failedPaths[path] = true
+	}
+	var done, retry []fsops.FileInfo
+	for _, info := range batch {
+		if failedPaths[info.AbsolutePath] {
+			retry = append(retry, info)
+		} else {
+			done = append(done, info)
+		}
+	}
+	return done, retry
+}
+
+func withoutPartialDownloads(infos []fsops.FileInfo) []fsops.FileInfo {
+	kept := infos[:0:0]
+	for _, info := range infos {
+		partial := false
+		for _, extension := range partialDownloadExtensions {
+			if strings.EqualFold(info.Extension, extension) {
+				partial = true
+				break
+			}
+		}
+		if !partial {
+			kept = append(kept, info)
+		}
+	}
+	return kept
+}
+
+type observation struct {
+	info        fsops.FileInfo
+	stableSince time.Time
+}
+
+// tracker turns successive directory scans into batches of settled, unprocessed files.
+type tracker struct {
+	settle, window time.Duration
+	watching       map[string]observation
+	ready          []fsops.FileInfo
+	windowOpened   time.Time
+}
+
+func newTracker(settle, window time.Duration) *tracker {
+	return &tracker{settle: settle, window: window, watching: map[string]observation{}}
+}
+
+// observe records one scan taken at now and returns a batch once the window that opened
+// with the first settled file has elapsed.
+func (t *tracker) observe(now time.Time, infos []fsops.FileInfo, processed func(fsops.FileInfo) bool) []fsops.FileInfo {
+	present := make(map[string]fsops.FileInfo, len(infos))
+	for _, info := range infos {
+		present[info.AbsolutePath] = info
+	}
+	ready := t.ready[:0:0]
+	for _, info := range t.ready {
+		if current, ok := present[info.AbsolutePath]; ok && sameFile(current, info) {
+			ready = append(ready, info)
+		}
+	}
+	t.ready = ready
+	for path := range t.watching {
+		if _, ok := present[path]; !ok {
+			delete(t.watching, path)
+		}
+	}
+
+	for _, info := range infos {
+		if processed(info) || t.isReady(info.AbsolutePath) {
+			continue
+		}
+		seen, ok := t.watching[info.AbsolutePath]
+		if !ok || !sameFile(seen.info, info) {
+			t.watching[info.AbsolutePath] = observation{info: info, stableSince: now}
+			continue
+		}
+		if now.Sub(seen.stableSince) < t.settle {
+			continue
+		}
+		delete(t.watching, info.AbsolutePath)
+		if len(t.ready) == 0 {
+			t.windowOpened = now
+		}
+		t.ready = append(t.ready, info)
+	}
+
+	if len(t.ready) == 0 || now.Sub(t.windowOpened) < t.window {
+		return nil
+	}
+	batch := t.ready
+	t.ready = nil
+	return batch
+}
+
+func (t *tracker) isReady(path string) bool {
+	for _, info := range t.ready {
+		if info.AbsolutePath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func sameFile(a, b fsops.FileInfo) bool {
+	return a.SizeBytes == b.SizeBytes && a.ModTime.Equal(b.ModTime)
+}
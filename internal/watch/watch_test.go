@@ -0,0 +1,257 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+func fileInfo(path string, size int64, modTime time.Time) fsops.FileInfo {
+	return fsops.FileInfo{AbsolutePath: path, SizeBytes: size, ModTime: modTime}
+}
+
+func TestTrackerBatchesSettledFilesAfterWindow(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTracker(5*time.Second, 10*time.Second)
+	never := func(fsops.FileInfo) bool { return false }
+
+	growing := fileInfo("/downloads/movie.mkv", 100, start)
+	done := fileInfo("/downloads/report.pdf", 10, start)
+
+	steps := []struct {
+		name     string
+		offset   time.Duration
+		infos    []fsops.FileInfo
+		expected int
+	}{
+		{name: "first sighting", offset: 0, infos: []fsops.FileInfo{done, growing}},
+		{name: "still settling", offset: 3 * time.Second, infos: []fsops.FileInfo{done, fileInfo(growing.AbsolutePath, 200, start.Add(3*time.Second))}},
+		{name: "settled, window opens", offset: 6 * time.Second, infos: []fsops.FileInfo{done, fileInfo(growing.AbsolutePath, 300, start.Add(6*time.Second))}},
+		{name: "second file settles inside window", offset: 12 * time.Second, infos: []fsops.FileInfo{done, fileInfo(growing.AbsolutePath, 300, start.Add(6*time.Second))}},
+		{name: "window elapsed", offset: 16 * time.Second, infos: []fsops.FileInfo{done, fileInfo(growing.AbsolutePath, 300, start.Add(6*time.Second))}, expected: 2},
+		{name: "nothing new", offset: 30 * time.Second, infos: nil},
+	}
+	for _, step := range steps {
+		batch := tracker.observe(start.Add(step.offset), step.infos, never)
+		if len(batch) != step.expected {
+			t.Fatalf("%s: expected %d file(s), got %+v", step.name, step.expected, batch)
+		}
+	}
+}
+
+func TestTrackerSkipsProcessedFiles(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTracker(time.Second, time.Second)
+	state := &State{Files: map[string]StateEntry{}}
+	info := fileInfo("/downloads/a.txt", 1, start)
+	state.Mark([]fsops.FileInfo{info}, start)
+
+	for _, offset := range []time.Duration{0, 2 * time.Second, 4 * time.Second} {
+		if batch := tracker.observe(start.Add(offset), []fsops.FileInfo{info}, state.Processed); len(batch) != 0 {
+			t.Fatalf("expected processed file to be skipped, got %+v", batch)
+		}
+	}
+
+	replaced := fileInfo(info.AbsolutePath, 2, start.Add(time.Minute))
+	if state.Processed(replaced) {
+		t.Fatalf("expected a new download under the same name to be unprocessed")
+	}
+}
+
+func TestWithoutPartialDownloads(t *testing.T) {
+	infos := []fsops.FileInfo{
+		{AbsolutePath: "/d/a.zip", Extension: ".zip"},
+		{AbsolutePath: "/d/b.zip.crdownload", Extension: ".crdownload"},
+		{AbsolutePath: "/d/c.iso.PART", Extension: ".PART"},
+	}
+	kept := withoutPartialDownloads(infos)
+	if len(kept) != 1 || kept[0].AbsolutePath != "/d/a.zip" {
+		t.Fatalf("expected only the finished download, got %+v", kept)
+	}
+}
+
+func TestRunMarksHandledFilesInState(t *testing.T) {
+	mem := fsops.NewMem()
+	fs := fsops.NewOps(mem)
+	if err := mem.WriteFile("/downloads/a.txt", []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var handled [][]string
+	watcher := Watcher{
+		FS:        fs,
+		Root:      "/downloads",
+		StatePath: "/staging/.llm-tasks-watch/state.json",
+		Options:   Options{Interval: time.Millisecond, Settle: time.Second, Window: time.Second},
+		now: func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	}
+	handler := func(_ context.Context, paths []string) ([]string, error) {
+		handled = append(handled, paths)
+		cancel()
+		return nil, nil
+	}
+	if err := watcher.Run(ctx, handler); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(handled) != 1 || len(handled[0]) != 1 || handled[0][0] != "/downloads/a.txt" {
+		t.Fatalf("expected a.txt to be handled once, got %v", handled)
+	}
+
+	state, err := LoadState(fs, watcher.StatePath)
+	if err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if _, ok := state.Files["/downloads/a.txt"]; !ok {
+		t.Fatalf("expected a.txt in the state file, got %+v", state.Files)
+	}
+}
+
+func TestRunRetriesFailedBatchesWithBackoff(t *testing.T) {
+	mem := fsops.NewMem()
+	fs := fsops.NewOps(mem)
+	if err := mem.WriteFile("/downloads/a.txt", []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls []time.Time
+	watcher := Watcher{
+		FS:        fs,
+		Root:      "/downloads",
+		StatePath: "/staging/state.json",
+		Options:   Options{Interval: time.Millisecond, Settle: time.Second, Window: time.Second, Backoff: time.Minute, MaxRetries: 5},
+		now: func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	}
+	handler := func(context.Context, []string) ([]string, error) {
+		calls = append(calls, clock)
+		if len(calls) == 3 {
+			cancel()
+			return nil, nil
+		}
+		return nil, errors.New("model unavailable")
+	}
+	if err := watcher.Run(ctx, handler); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("expected the failed batch to be retried, got %d call(s)", len(calls))
+	}
+	if first, second := calls[1].Sub(calls[0]), calls[2].Sub(calls[1]); first < time.Minute || second < 2*time.Minute {
+		t.Fatalf("expected retries to back off 1m then 2m, got %v then %v", first, second)
+	}
+}
+
+func TestRunRetriesOnlyTheFilesOfAFailedSubBatch(t *testing.T) {
+	mem := fsops.NewMem()
+	fs := fsops.NewOps(mem)
+	for _, name := range []string{"/downloads/a.txt", "/downloads/b.txt"} {
+		if err := mem.WriteFile(name, []byte(name), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var handled [][]string
+	watcher := Watcher{
+		FS:        fs,
+		Root:      "/downloads",
+		StatePath: "/staging/state.json",
+		Options:   Options{Interval: time.Millisecond, Settle: time.Second, Window: time.Second, Backoff: time.Minute, MaxRetries: 5},
+		now: func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	}
+	// the first run sorts a.txt but the sub-batch holding b.txt fails; the second run sorts b.txt
+	handler := func(_ context.Context, paths []string) ([]string, error) {
+		handled = append(handled, paths)
+		if len(handled) == 1 {
+			return []string{"/downloads/b.txt"}, nil
+		}
+		cancel()
+		return nil, nil
+	}
+	if err := watcher.Run(ctx, handler); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(handled) != 2 || len(handled[0]) != 2 || len(handled[1]) != 1 || handled[1][0] != "/downloads/b.txt" {
+		t.Fatalf("expected both files, then only b.txt, got %v", handled)
+	}
+
+	state, err := LoadState(fs, watcher.StatePath)
+	if err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if entry := state.Files["/downloads/a.txt"]; entry.ProcessedAt.IsZero() || entry.Failures != 0 {
+		t.Fatalf("expected a.txt processed without failures, got %+v", entry)
+	}
+}
+
+func TestRunGivesUpAfterMaxRetries(t *testing.T) {
+	mem := fsops.NewMem()
+	fs := fsops.NewOps(mem)
+	if err := mem.WriteFile("/downloads/a.txt", []byte("a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	watcher := Watcher{
+		FS:        fs,
+		Root:      "/downloads",
+		StatePath: "/staging/state.json",
+		Options:   Options{Interval: time.Millisecond, Settle: time.Second, Window: time.Second, Backoff: time.Second, MaxRetries: 2},
+		now: func() time.Time {
+			clock = clock.Add(time.Second)
+			if clock.Minute() >= 2 {
+				cancel()
+			}
+			return clock
+		},
+	}
+	handler := func(context.Context, []string) ([]string, error) {
+		calls++
+		return nil, errors.New("invalid API key")
+	}
+	if err := watcher.Run(ctx, handler); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected two attempts before giving up, got %d", calls)
+	}
+
+	state, err := LoadState(fs, watcher.StatePath)
+	if err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	info, err := fs.Inventory("/downloads")
+	if err != nil || len(info) != 1 {
+		t.Fatalf("inventory: %v %+v", err, info)
+	}
+	if !state.Deferred(info[0], clock, 2) || state.Processed(info[0]) {
+		t.Fatalf("expected the file to stay deferred and unprocessed after a restart, got %+v", state.Files)
+	}
+	changed := fileInfo(info[0].AbsolutePath, info[0].SizeBytes+1, info[0].ModTime)
+	if state.Deferred(changed, clock, 2) {
+		t.Fatalf("expected a new version of the file to be retried")
+	}
+}
@@ -4,21 +4,12 @@ import (
 	"os"
 
 	llmtasks "github.com/temirov/llm-tasks/cmd/llm-tasks"
-	"go.uber.org/zap"
 )
 
+// main leaves reporting to the command: cobra prints the error to stderr and run logs go
+// through the logger built from common.logging.
 func main() {
-	logger := zap.Must(zap.NewProduction())
-
-	executionErr := llmtasks.Execute()
-	if executionErr != nil {
-		logger.Error("command execution failed", zap.Error(executionErr))
-		_ = logger.Sync()
-		os.Exit(1)
-	}
-
-	syncErr := logger.Sync()
-	if syncErr != nil {
+	if executionErr := llmtasks.Execute(); executionErr != nil {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,215 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultBreakingFooter = "**Upgrade notes:** This release contains breaking changes."
+	otherSectionTitle     = "Other"
+	shortSHALength        = 7
+)
+
+// citedCommitTypes are the types whose commits must be cited by short SHA in the generated section.
+var citedCommitTypes = map[string]bool{"feat": true, "fix": true}
+
+var (
+	conventionalSubjectPattern = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?(!)?:\s*(.+)$`)
+	commitSHAPattern           = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	// mergeSubjectPattern matches the subjects git and GitHub give merge commits.
+	mergeSubjectPattern = regexp.MustCompile(`^Merge (pull request|branch|remote-tracking branch|tag) `)
+)
+
+// conventionalCommit is a gathered commit parsed as a Conventional Commit; Type is empty
+// when the subject does not follow the convention. PullRequest is the number cited in the
+// subject, e.g. "12" for "feat: widgets (#12)".
+type conventionalCommit struct {
+	SHA         string
+	Type        string
+	Scope       string
+	Subject     string
+	Breaking    bool
+	PullRequest string
+}
+
+func (c conventionalCommit) shortSHA() string {
+	return c.SHA[:min(shortSHALength, len(c.SHA))]
+}
+
+type commitSection struct {
+	Title   string
+	Commits []conventionalCommit
+}
+
+// parseGatheredCommits reads the gathered git log, which is either the JSON produced by
+// `source: git` or text piped on stdin in `git log` or `git log --oneline` format.
+func parseGatheredCommits(gitLog string) []conventionalCommit {
+	trimmed := strings.TrimSpace(gitLog)
+	if strings.HasPrefix(trimmed, "[") {
+		var commits []Commit
+		if err := json.Unmarshal([]byte(trimmed), &commits); err == nil {
+			parsed := make([]conventionalCommit, 0, len(commits))
+			for _, commit := range commits {
+				footers := []string{commit.Body}
+				for _, trailer := range commit.Trailers {
+					footers = append(footers, trailer.Key+": "+trailer.Value)
+				}
+				parsed = append(parsed, parseConventional(commit.SHA, commit.Subject, strings.Join(footers, "\n")))
+			}
+			return parsed
+		}
+	}
+	return parseTextLog(trimmed)
+}
+
+// parseTextLog understands the default `git log` layout (commit/Author/Date headers with an
+// indented message) and one commit per line, optionally prefixed by its SHA. Merge commits are
+// dropped, matching the --no-merges log of `source: git`.
+func parseTextLog(gitLog string) []conventionalCommit {
+	type rawCommit struct {
+		sha, subject string
+		body         []string
+		merge        bool
+	}
+	var (
+		commits []conventionalCommit
+		current *rawCommit
+		full    bool
+	)
+	flush := func() {
+		if current != nil && current.subject != "" && !current.merge && !mergeSubjectPattern.MatchString(current.subject) {
+			commits = append(commits, parseConventional(current.sha, current.subject, strings.Join(current.body, "\n")))
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(gitLog, "\n") {
+		trimmed := strings.TrimSpace(line)
+		fields := strings.Fields(trimmed)
+		switch {
+		case len(fields) == 2 && fields[0] == "commit" && commitSHAPattern.MatchString(fields[1]):
+			flush()
+			current, full = &rawCommit{sha: fields[1]}, true
+		case full && current != nil && strings.HasPrefix(line, "Merge:"):
+			current.merge = true
+		case full:
+			if current == nil || trimmed == "" || !(strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")) {
+				continue
+			}
+			if current.subject == "" {
+				current.subject = trimmed
+			} else {
+				current.body = append(current.body, trimmed)
+			}
+		case trimmed == "":
+		case isBreakingFooter(trimmed) && current != nil:
+			current.body = append(current.body, trimmed)
+		default:
+			flush()
+			current = &rawCommit{subject: trimmed}
+			if len(fields) > 1 && commitSHAPattern.MatchString(fields[0]) {
+				current.sha, current.subject = fields[0], strings.TrimSpace(strings.TrimPrefix(trimmed, fields[0]))
+			}
+		}
+	}
+	flush()
+	return commits
+}
+
+func parseConventional(sha, subject, footers string) conventionalCommit {
+	commit := conventionalCommit{SHA: sha, Subject: strings.TrimSpace(subject)}
+	if match := pullRequestPattern.FindStringSubmatch(commit.Subject); match != nil {
+		commit.PullRequest = match[1]
+	}
+	if match := conventionalSubjectPattern.FindStringSubmatch(commit.Subject); match != nil {
+		commit.Type = strings.ToLower(match[1])
+		commit.Scope = strings.Trim(match[2], "()")
+		commit.Breaking = match[3] == "!"
+		commit.Subject = match[4]
+	}
+	for _, line := range strings.Split(footers, "\n") {
+		if isBreakingFooter(strings.TrimSpace(line)) {
+			commit.Breaking = true
+		}
+	}
+	return commit
+}
+
+func isBreakingFooter(line string) bool {
+	return strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:")
+}
+
+// groupCommits buckets commits into the configured sections via recipe.mapping, keeping the
+// sections' order; unmapped types and non-conventional commits go to a trailing "Other" group.
+func groupCommits(commits []conventionalCommit, cfg Config) []commitSection {
+	sections := make([]commitSection, 0, len(cfg.Recipe.Format.Sections)+1)
+	index := map[string]int{}
+	for _, section := range cfg.Recipe.Format.Sections {
+		index[section.Title] = len(sections)
+		sections = append(sections, commitSection{Title: section.Title})
+	}
+	var other commitSection
+	for _, commit := range commits {
+		title := strings.TrimSpace(cfg.Recipe.Mapping[commit.Type])
+		position, ok := index[title]
+		if commit.Type == "" || !ok {
+			other.Commits = append(other.Commits, commit)
+			continue
+		}
+		sections[position].Commits = append(sections[position].Commits, commit)
+	}
+	grouped := sections[:0]
+	for _, section := range sections {
+		if len(section.Commits) > 0 {
+			grouped = append(grouped, section)
+		}
+	}
+	if len(other.Commits) > 0 {
+		other.Title = otherSectionTitle
+		grouped = append(grouped, other)
+	}
+	return grouped
+}
+
+func hasBreaking(commits []conventionalCommit) bool {
+	for _, commit := range commits {
+		if commit.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// uncitedCommits lists feat/fix commits whose short SHA does not appear in the generated section.
+func uncitedCommits(commits []conventionalCommit, md string) []conventionalCommit {
+	var missing []conventionalCommit
+	for _, commit := range commits {
+		if citedCommitTypes[commit.Type] && commit.SHA != "" && !strings.Contains(md, commit.shortSHA()) {
+			missing = append(missing, commit)
+		}
+	}
+	return missing
+}
+
+func formatCommitLine(commit conventionalCommit) string {
+	var line strings.Builder
+	line.WriteString("- ")
+	if commit.SHA != "" {
+		line.WriteString(commit.shortSHA())
+		line.WriteString(" ")
+	}
+	if commit.Type != "" {
+		line.WriteString(commit.Type)
+		if commit.Scope != "" {
+			fmt.Fprintf(&line, "(%s)", commit.Scope)
+		}
+		line.WriteString(": ")
+	}
+	line.WriteString(commit.Subject)
+	if commit.Breaking {
+		line.WriteString(" [BREAKING]")
+	}
+	return line.String()
+}
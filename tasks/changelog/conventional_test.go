@@ -0,0 +1,148 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const mappingConfigYAML = `
+task: changelog
+recipe:
+  format:
+    heading: "## [${version}]"
+    sections:
+      - { title: "Features" }
+      - { title: "Fixes" }
+      - { title: "Maintenance" }
+    footer: "**Upgrade notes:** No breaking changes."
+    breaking_footer: "**Upgrade notes:** Breaking changes below."
+  mapping:
+    feat: "Features"
+    fix: "Fixes"
+    ci: "Maintenance"
+    chore: "Maintenance"
+`
+
+func mappingConfig(t *testing.T) Config {
+	t.Helper()
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(mappingConfigYAML), &cfg); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	return cfg
+}
+
+func TestParseGatheredCommits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		gitLog   string
+		expected []conventionalCommit
+	}{
+		{
+			name:   "oneline",
+			gitLog: "abc1234 feat(api): add widgets\n1234567 fix!: drop v1 endpoint\nUpdate README\n",
+			expected: []conventionalCommit{
+				{SHA: "abc1234", Type: "feat", Scope: "api", Subject: "add widgets"},
+				{SHA: "1234567", Type: "fix", Subject: "drop v1 endpoint", Breaking: true},
+				{Subject: "Update README"},
+			},
+		},
+		{
+			name: "full git log with breaking footer",
+			gitLog: "commit 0123456789abcdef0123456789abcdef01234567\nAuthor: Ada <ada@example.com>\nDate:   Sat Mar 1 12:00:00 2025\n\n" +
+				"    feat: new config format\n\n    BREAKING CHANGE: old keys are rejected\n\n" +
+				"commit 89abcdef0123456789abcdef0123456789abcdef\nAuthor: Ada <ada@example.com>\n\n    docs: explain config\n",
+			expected: []conventionalCommit{
+				{SHA: "0123456789abcdef0123456789abcdef01234567", Type: "feat", Subject: "new config format", Breaking: true},
+				{SHA: "89abcdef0123456789abcdef0123456789abcdef", Type: "docs", Subject: "explain config"},
+			},
+		},
+		{
+			name:   "structured git source",
+			gitLog: `[{"sha":"fedcba9876","author":"Ada","email":"a@x","subject":"ci: cache modules","trailers":[{"key":"BREAKING-CHANGE","value":"needs go 1.25"}]}]`,
+			expected: []conventionalCommit{
+				{SHA: "fedcba9876", Type: "ci", Subject: "cache modules", Breaking: true},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := parseGatheredCommits(testCase.gitLog)
+			if len(got) != len(testCase.expected) {
+				t.Fatalf("expected %+v, got %+v", testCase.expected, got)
+			}
+			for i := range got {
+				if got[i] != testCase.expected[i] {
+					t.Fatalf("commit %d: expected %+v, got %+v", i, testCase.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupCommitsFollowsSectionOrder(t *testing.T) {
+	commits := parseGatheredCommits("a000001 chore: bump deps\na000002 fix: crash\na000003 feat: widgets\na000004 perf: faster\nplain message\n")
+	grouped := groupCommits(commits, mappingConfig(t))
+
+	var titles []string
+	for _, section := range grouped {
+		titles = append(titles, section.Title)
+	}
+	if got := strings.Join(titles, "|"); got != "Features|Fixes|Maintenance|Other" {
+		t.Fatalf("unexpected sections %q", got)
+	}
+	if len(grouped[3].Commits) != 2 {
+		t.Fatalf("expected unmapped and non-conventional commits in Other, got %+v", grouped[3].Commits)
+	}
+}
+
+func TestVerifyRequiresCitedCommitsAndBreakingFooter(t *testing.T) {
+	task := NewFromConfig(mappingConfig(t))
+	task.version = "2.0.0"
+	task.gitLog = "abc1234 feat!: new API\ndef5678 fix: typo\n9999999 chore: tidy\n"
+	task.commits = parseGatheredCommits(task.gitLog)
+
+	request, err := task.Prompt(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	if !strings.Contains(request.UserPrompt, "### Features\n- abc1234 feat: new API [BREAKING]\n") {
+		t.Fatalf("expected pre-grouped commits in the prompt:\n%s", request.UserPrompt)
+	}
+	if !strings.Contains(request.UserPrompt, "**Upgrade notes:** Breaking changes below.") {
+		t.Fatalf("expected the breaking footer in the prompt:\n%s", request.UserPrompt)
+	}
+
+	sections := "## [2.0.0]\n\n### Features\n\n- New API (abc1234)\n\n### Maintenance\n\n- Tidy (9999999)\n\n### Fixes\n"
+	testCases := []struct {
+		name           string
+		md             string
+		expectedReason string
+	}{
+		{name: "uncited fix", md: sections + "\n**Upgrade notes:** Breaking changes below.\n", expectedReason: "uncited-commits"},
+		{name: "missing breaking footer", md: sections + "- Typo (def5678)\n\n**Upgrade notes:** No breaking changes.\n", expectedReason: "missing-breaking-footer"},
+		{name: "complete", md: sections + "- Typo (def5678)\n\n**Upgrade notes:** Breaking changes below.\n"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, _, refine, err := task.Verify(context.Background(), nil, pipeline.LLMResponse{RawText: testCase.md})
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if testCase.expectedReason == "" {
+				if !ok {
+					t.Fatalf("expected acceptance, got refine %+v", refine)
+				}
+				return
+			}
+			if ok || refine == nil || refine.Reason != testCase.expectedReason {
+				t.Fatalf("expected refine %q, got ok=%v refine=%+v", testCase.expectedReason, ok, refine)
+			}
+		})
+	}
+}
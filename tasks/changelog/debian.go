@@ -0,0 +1,107 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDebianDistribution = "unstable"
+	defaultDebianUrgency      = "medium"
+	debianTrailerDateLayout   = "Mon, 02 Jan 2006 15:04:05 -0700"
+)
+
+// debianEntry renders the release as one debian/changelog entry: sections become top-level
+// "*" items with their bullets nested below, and the trailer carries the maintainer and date.
+func (t *Task) debianEntry(release Release, now time.Time) (string, error) {
+	settings := t.cfg.Apply.Debian
+	if strings.TrimSpace(settings.Package) == "" {
+		return "", errors.New("apply.debian.package is required for mode debian")
+	}
+	maintainer := strings.TrimSpace(settings.Maintainer)
+	if maintainer == "" {
+		name, email := strings.TrimSpace(os.Getenv("DEBFULLNAME")), strings.TrimSpace(os.Getenv("DEBEMAIL"))
+		if name == "" || email == "" {
+			return "", errors.New("apply.debian.maintainer is required for mode debian (or set DEBFULLNAME and DEBEMAIL)")
+		}
+		maintainer = fmt.Sprintf("%s <%s>", name, email)
+	}
+	date := now
+	if parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(release.Date)); err == nil {
+		date = parsed
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s (%s) %s; urgency=%s\n\n",
+		strings.TrimSpace(settings.Package),
+		debianVersion(release.Version),
+		coalesce(strings.TrimSpace(settings.Distribution), defaultDebianDistribution),
+		coalesce(strings.TrimSpace(settings.Urgency), defaultDebianUrgency),
+	)
+	for _, section := range release.Sections {
+		if len(section.Bullets) == 0 {
+			continue
+		}
+		fmt.Fprintf(&out, "  * %s:\n", section.Title)
+		for _, bullet := range section.Bullets {
+			for index, line := range strings.Split(bullet, "\n") {
+				if index == 0 {
+					fmt.Fprintf(&out, "    - %s\n", line)
+				} else {
+					fmt.Fprintf(&out, "      %s\n", strings.TrimSpace(line))
+				}
+			}
+		}
+	}
+	if release.Footer != "" {
+		for _, line := range strings.Split(release.Footer, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&out, "  %s\n", line)
+			}
+		}
+	}
+	fmt.Fprintf(&out, "\n -- %s  %s\n", maintainer, date.Format(debianTrailerDateLayout))
+	return out.String(), nil
+}
+
+// debianVersion drops the "v" of a git tag; Debian versions must start with a digit.
+func debianVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// upsertDebianEntry replaces the entry for the same version in an existing debian/changelog,
+// or puts the new entry on top as dch does.
+func upsertDebianEntry(existing, entry, version string) (string, applyAction) {
+	version = debianVersion(version)
+	var entries []string
+	for _, line := range strings.SplitAfter(existing, "\n") {
+		if line != "" && !strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+			entries = append(entries, "")
+		}
+		if len(entries) == 0 {
+			entries = append(entries, "")
+		}
+		entries[len(entries)-1] += line
+	}
+	for index, current := range entries {
+		if debianEntryVersion(current) == version {
+			entries[index] = strings.TrimRight(entry, "\n") + current[len(strings.TrimRight(current, "\n")):]
+			return strings.Join(entries, ""), actionReplaced
+		}
+	}
+	if strings.TrimSpace(existing) == "" {
+		return entry, actionPrepended
+	}
+	return entry + "\n" + strings.TrimLeft(existing, "\n"), actionPrepended
+}
+
+func debianEntryVersion(entry string) string {
+	start, end := strings.Index(entry, "("), strings.Index(entry, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(entry[start+1 : end])
+}
@@ -0,0 +1,69 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "identical",
+			before:   "a\nb\n",
+			after:    "a\nb\n",
+			expected: "",
+		},
+		{
+			name:   "prepend to empty file",
+			before: "",
+			after:  "## [1.0.0]\n\n- x\n",
+			expected: "--- CHANGELOG.md\n+++ CHANGELOG.md (dry-run)\n" +
+				"@@ -0,0 +1,3 @@\n+## [1.0.0]\n+\n+- x\n",
+		},
+		{
+			name:   "prepend keeps limited context",
+			before: "## [0.9.0]\n- a\n- b\n- c\n- d\n",
+			after:  "## [1.0.0]\n- x\n\n## [0.9.0]\n- a\n- b\n- c\n- d\n",
+			expected: "--- CHANGELOG.md\n+++ CHANGELOG.md (dry-run)\n" +
+				"@@ -1,3 +1,6 @@\n+## [1.0.0]\n+- x\n+\n ## [0.9.0]\n - a\n - b\n",
+		},
+		{
+			name:   "replacement in the middle",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: "--- CHANGELOG.md\n+++ CHANGELOG.md (dry-run)\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := unifiedDiff("CHANGELOG.md", testCase.before, testCase.after)
+			if got != testCase.expected {
+				t.Fatalf("unexpected diff\nexpected:\n%s\ngot:\n%s", testCase.expected, got)
+			}
+		})
+	}
+}
+
+// A long unchanged tail must not reach the quadratic LCS table: 100k lines would need
+// 80 GB for it, so this only finishes because the prefix and suffix are trimmed first.
+func TestUnifiedDiffLargeUnchangedTail(t *testing.T) {
+	var tail strings.Builder
+	for line := range 100000 {
+		fmt.Fprintf(&tail, "- change %d\n", line)
+	}
+	before := "# Changelog\n\n" + tail.String()
+	after := "# Changelog\n\n## [2.0.0]\n- new\n\n" + tail.String()
+
+	expected := "--- CHANGELOG.md\n+++ CHANGELOG.md (dry-run)\n" +
+		"@@ -1,5 +1,8 @@\n # Changelog\n \n+## [2.0.0]\n+- new\n+\n - change 0\n - change 1\n - change 2\n"
+	if got := unifiedDiff("CHANGELOG.md", before, after); got != expected {
+		t.Fatalf("unexpected diff\nexpected:\n%s\ngot:\n%s", expected, got)
+	}
+}
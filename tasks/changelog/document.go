@@ -0,0 +1,225 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	applyModePrint   = "print"
+	applyModePrepend = "prepend"
+	applyModeUpsert  = "upsert"
+	applyModeReplace = "replace"
+	applyModeJSON    = "json"
+	applyModeGitHub  = "github"
+	applyModeDebian  = "debian"
+
+	versionHeadingPrefix = "## "
+	unreleasedVersion    = "unreleased"
+)
+
+// applyAction phrases the change for the run summary, applied and dry-run.
+type applyAction struct {
+	done    string
+	planned string
+}
+
+var (
+	actionPrepended = applyAction{done: "prepended changelog to", planned: "would prepend changelog to"}
+	actionInserted  = applyAction{done: "inserted changelog section into", planned: "would insert changelog section into"}
+	actionReplaced  = applyAction{done: "replaced changelog section in", planned: "would replace changelog section in"}
+	actionWrote     = applyAction{done: "wrote changelog to", planned: "would write changelog to"}
+)
+
+// linkDefinitionPattern matches a Markdown link reference definition such as
+// "[1.0.0]: https://example.com/compare/v0.9.0...v1.0.0".
+var linkDefinitionPattern = regexp.MustCompile(`^\[[^\]]+\]:\s*\S+`)
+
+// defaultOutputPaths is where each file-writing mode writes when apply.output_path is empty.
+var defaultOutputPaths = map[string]string{
+	applyModePrepend: "./CHANGELOG.md",
+	applyModeUpsert:  "./CHANGELOG.md",
+	applyModeReplace: "./CHANGELOG.md",
+	applyModeJSON:    "./CHANGELOG.json",
+	applyModeGitHub:  "./RELEASE_NOTES.md",
+	applyModeDebian:  "./debian/changelog",
+}
+
+// render produces the new content of the output file for mode from the verified release.
+// JSON and GitHub bodies describe one release and replace the file; the changelog modes
+// merge the release into the existing content.
+func (t *Task) render(release Release, mode, existing string) (string, applyAction, error) {
+	switch mode {
+	case applyModeJSON:
+		out, err := release.JSON()
+		return out, actionWrote, err
+	case applyModeGitHub:
+		return release.GitHubBody(), actionWrote, nil
+	case applyModeDebian:
+		entry, err := t.debianEntry(release, time.Now())
+		if err != nil {
+			return "", applyAction{}, err
+		}
+		updated, action := upsertDebianEntry(existing, entry, release.Version)
+		return updated, action, nil
+	default:
+		return updateChangelog(existing, release.Markdown(), release.Version, mode, t.cfg.Apply.EnsureBlankLine)
+	}
+}
+
+// changelogDocument is an existing CHANGELOG.md split at its "## " version headings. The
+// preamble holds everything above the first released version: the title, introduction and a
+// Keep a Changelog "## [Unreleased]" block. The footer holds the link reference definitions
+// that end the file, which belong to no single version.
+type changelogDocument struct {
+	Preamble string
+	Sections []versionSection
+	Footer   string
+}
+
+// versionSection is one release block; Text runs from its heading up to the next one and
+// keeps its trailing blank lines.
+type versionSection struct {
+	Version string
+	Text    string
+}
+
+func parseChangelogDocument(content string) changelogDocument {
+	var document changelogDocument
+	var current *versionSection
+	var preamble strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if version, ok := headingVersion(line); ok && (current != nil || !strings.EqualFold(version, unreleasedVersion)) {
+			document.Sections = append(document.Sections, versionSection{Version: version})
+			current = &document.Sections[len(document.Sections)-1]
+		}
+		if current == nil {
+			preamble.WriteString(line)
+		} else {
+			current.Text += line
+		}
+	}
+	document.Preamble = preamble.String()
+	if last := len(document.Sections) - 1; last >= 0 {
+		document.Sections[last].Text, document.Footer = splitFooter(document.Sections[last].Text)
+	} else {
+		document.Preamble, document.Footer = splitFooter(document.Preamble)
+	}
+	return document
+}
+
+// splitFooter cuts the trailing run of link reference definitions (and the blank lines between
+// them) off text; blank lines before the first definition stay with text.
+func splitFooter(text string) (string, string) {
+	lines := strings.SplitAfter(text, "\n")
+	start := len(lines)
+	for index := len(lines) - 1; index >= 0; index-- {
+		trimmed := strings.TrimSpace(lines[index])
+		if trimmed == "" {
+			continue
+		}
+		if !linkDefinitionPattern.MatchString(trimmed) {
+			break
+		}
+		start = index
+	}
+	return strings.Join(lines[:start], ""), strings.Join(lines[start:], "")
+}
+
+// headingVersion extracts the version from "## [1.2.3] - 2025-01-05" or "## 1.2.3"; other
+// level-two headings such as "## Conventions" are not versions.
+func headingVersion(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, versionHeadingPrefix) {
+		return "", false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(trimmed, versionHeadingPrefix))
+	if strings.HasPrefix(rest, "[") {
+		if end := strings.Index(rest, "]"); end > 0 {
+			return strings.TrimSpace(rest[1:end]), true
+		}
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || !unicode.IsDigit(rune(fields[0][0])) {
+		return "", false
+	}
+	return fields[0], true
+}
+
+func (d changelogDocument) find(version string) int {
+	for index, section := range d.Sections {
+		if version != "" && section.Version == version {
+			return index
+		}
+	}
+	return -1
+}
+
+// insert places section below the preamble, above the newest released version, and keeps
+// the footer last.
+func (d changelogDocument) insert(section string, ensureBlankLine bool) string {
+	var out strings.Builder
+	if preamble := strings.TrimRight(d.Preamble, "\n"); strings.TrimSpace(preamble) != "" {
+		out.WriteString(preamble)
+		out.WriteString("\n\n")
+	}
+	out.WriteString(section)
+	out.WriteString("\n")
+	if ensureBlankLine {
+		out.WriteString("\n")
+	}
+	for index, existing := range d.Sections {
+		text := existing.Text
+		if index == 0 {
+			text = strings.TrimLeft(text, "\n")
+		}
+		out.WriteString(text)
+	}
+	if d.Footer != "" && !strings.HasSuffix(out.String(), "\n\n") {
+		out.WriteString("\n")
+	}
+	out.WriteString(d.Footer)
+	return out.String()
+}
+
+// replace swaps the block at index for section, keeping the blank lines that followed it.
+func (d changelogDocument) replace(index int, section string) string {
+	var out strings.Builder
+	out.WriteString(d.Preamble)
+	for position, existing := range d.Sections {
+		if position != index {
+			out.WriteString(existing.Text)
+			continue
+		}
+		out.WriteString(section)
+		out.WriteString(existing.Text[len(strings.TrimRight(existing.Text, "\n")):])
+	}
+	out.WriteString(d.Footer)
+	return out.String()
+}
+
+// updateChangelog applies section for version to existing content according to mode and
+// returns the new content with the action taken.
+func updateChangelog(existing, section, version, mode string, ensureBlankLine bool) (string, applyAction, error) {
+	document := parseChangelogDocument(existing)
+	index := document.find(strings.TrimSpace(version))
+	switch mode {
+	case applyModePrepend:
+		return document.insert(section, ensureBlankLine), actionPrepended, nil
+	case applyModeUpsert:
+		if index < 0 {
+			return document.insert(section, ensureBlankLine), actionInserted, nil
+		}
+		return document.replace(index, section), actionReplaced, nil
+	case applyModeReplace:
+		if index < 0 {
+			return "", applyAction{}, fmt.Errorf("replace: no section for version %q", version)
+		}
+		return document.replace(index, section), actionReplaced, nil
+	default:
+		return "", applyAction{}, fmt.Errorf("unknown apply.mode: %s", mode)
+	}
+}
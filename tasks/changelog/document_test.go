@@ -0,0 +1,150 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateChangelog(t *testing.T) {
+	const (
+		preamble = "# Changelog\n\nAll notable changes are documented here.\n\n## [Unreleased]\n\n- Pending work\n\n"
+		released = "## [1.1.0] - 2025-02-01\n\n- Old 1.1.0 notes\n\n## [1.0.0] - 2025-01-01\n\n- First release\n"
+		section  = "## [1.1.0] - 2025-02-02\n\n- New 1.1.0 notes"
+		upcoming = "## [1.2.0] - 2025-03-01\n\n- Next"
+		links    = "[1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n[1.0.0]: https://example.com/releases/v1.0.0\n"
+	)
+	testCases := []struct {
+		name           string
+		existing       string
+		section        string
+		version        string
+		mode           string
+		expected       string
+		expectedAction applyAction
+		expectedErr    string
+	}{
+		{
+			name:           "prepend to a file without preamble",
+			existing:       released,
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModePrepend,
+			expected:       upcoming + "\n\n" + released,
+			expectedAction: actionPrepended,
+		},
+		{
+			name:           "prepend below the unreleased block",
+			existing:       preamble + released,
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModePrepend,
+			expected:       preamble + upcoming + "\n\n" + released,
+			expectedAction: actionPrepended,
+		},
+		{
+			name:           "upsert replaces the same version in place",
+			existing:       preamble + released,
+			section:        section,
+			version:        "1.1.0",
+			mode:           applyModeUpsert,
+			expected:       preamble + section + "\n\n## [1.0.0] - 2025-01-01\n\n- First release\n",
+			expectedAction: actionReplaced,
+		},
+		{
+			name:           "upsert inserts a new version",
+			existing:       preamble + released,
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModeUpsert,
+			expected:       preamble + upcoming + "\n\n" + released,
+			expectedAction: actionInserted,
+		},
+		{
+			name:           "upsert into an empty file",
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModeUpsert,
+			expected:       upcoming + "\n\n",
+			expectedAction: actionInserted,
+		},
+		{
+			name:           "replace the last section keeps the file ending",
+			existing:       released,
+			section:        "## [1.0.0] - 2025-01-01\n\n- Rewritten",
+			version:        "1.0.0",
+			mode:           applyModeReplace,
+			expected:       "## [1.1.0] - 2025-02-01\n\n- Old 1.1.0 notes\n\n## [1.0.0] - 2025-01-01\n\n- Rewritten\n",
+			expectedAction: actionReplaced,
+		},
+		{
+			name:           "upsert keeps trailing link definitions last",
+			existing:       released + "\n" + links,
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModeUpsert,
+			expected:       upcoming + "\n\n" + released + "\n" + links,
+			expectedAction: actionInserted,
+		},
+		{
+			name:           "replace the last section keeps trailing link definitions",
+			existing:       released + "\n" + links,
+			section:        "## [1.0.0] - 2025-01-01\n\n- Rewritten",
+			version:        "1.0.0",
+			mode:           applyModeReplace,
+			expected:       "## [1.1.0] - 2025-02-01\n\n- Old 1.1.0 notes\n\n## [1.0.0] - 2025-01-01\n\n- Rewritten\n\n" + links,
+			expectedAction: actionReplaced,
+		},
+		{
+			name:           "non-version headings stay in the preamble",
+			existing:       "# Changelog\n\n## Conventions\n\nWe use semver.\n\n" + released,
+			section:        upcoming,
+			version:        "1.2.0",
+			mode:           applyModeUpsert,
+			expected:       "# Changelog\n\n## Conventions\n\nWe use semver.\n\n" + upcoming + "\n\n" + released,
+			expectedAction: actionInserted,
+		},
+		{
+			name:        "replace requires the version",
+			existing:    released,
+			section:     upcoming,
+			version:     "1.2.0",
+			mode:        applyModeReplace,
+			expectedErr: `no section for version "1.2.0"`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, action, err := updateChangelog(testCase.existing, testCase.section, testCase.version, testCase.mode, true)
+			if testCase.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedErr) {
+					t.Fatalf("expected error %q, got %v", testCase.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if got != testCase.expected {
+				t.Fatalf("unexpected changelog\nexpected:\n%q\ngot:\n%q", testCase.expected, got)
+			}
+			if action != testCase.expectedAction {
+				t.Fatalf("expected action %+v, got %+v", testCase.expectedAction, action)
+			}
+		})
+	}
+}
+
+func TestUpdateChangelogUpsertIsIdempotent(t *testing.T) {
+	const section = "## [2.0.0] - 2025-04-01\n\n- Big release"
+	once, _, err := updateChangelog("# Changelog\n\n## [1.0.0]\n\n- First\n", section, "2.0.0", applyModeUpsert, true)
+	if err != nil {
+		t.Fatalf("first upsert: %v", err)
+	}
+	twice, _, err := updateChangelog(once, section, "2.0.0", applyModeUpsert, true)
+	if err != nil {
+		t.Fatalf("second upsert: %v", err)
+	}
+	if once != twice || strings.Count(twice, "## [2.0.0]") != 1 {
+		t.Fatalf("expected re-running upsert to leave the file unchanged, got:\n%s", twice)
+	}
+}
@@ -0,0 +1,173 @@
+package changelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	gitLogSourceStdin = "stdin"
+	gitLogSourceGit   = "git"
+	defaultGitTo      = "HEAD"
+
+	// git log fields are separated by US (0x1f) and commits by RS (0x1e), which never occur in commit text.
+	gitFieldSeparator  = "\x1f"
+	gitRecordSeparator = "\x1e"
+	gitLogFormat       = "%H%x1f%an%x1f%ae%x1f%s%x1f%b%x1f%(trailers:only,unfold)%x1e"
+)
+
+// Commit is one entry of a collected git log as it is shown to the model.
+type Commit struct {
+	SHA      string    `json:"sha"`
+	Author   string    `json:"author"`
+	Email    string    `json:"email"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body,omitempty"`
+	Trailers []Trailer `json:"trailers,omitempty"`
+}
+
+// Trailer is a "Key: value" line from the end of a commit message, e.g. Co-authored-by.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// gitRange resolves the configured refs: an empty from means the latest tag reachable from to,
+// or the whole history when the repository has no tags. When to itself carries that tag (the
+// release was tagged before the changelog was written), the tag before it is used instead.
+func gitRange(ctx context.Context, repoPath, from, to string) (string, string, error) {
+	to = coalesce(strings.TrimSpace(to), defaultGitTo)
+	from = strings.TrimSpace(from)
+	if from != "" {
+		return from, to, nil
+	}
+	tag, err := latestTag(ctx, repoPath, to)
+	if err != nil || tag == "" {
+		return "", to, err
+	}
+	out, err := runGit(ctx, repoPath, "rev-parse", tag+"^{commit}", to+"^{commit}")
+	if err != nil {
+		return "", "", err
+	}
+	if commits := strings.Fields(out); len(commits) != 2 || commits[0] != commits[1] {
+		return tag, to, nil
+	}
+	if _, err := runGit(ctx, repoPath, "rev-parse", "--verify", "--quiet", to+"^"); err != nil {
+		// to is the root commit, so the whole history is the release
+		return "", to, nil
+	}
+	tag, err = latestTag(ctx, repoPath, to+"^")
+	if err != nil {
+		return "", "", err
+	}
+	return tag, to, nil
+}
+
+// latestTag returns the newest tag reachable from revision, or "" when there is none.
+func latestTag(ctx context.Context, repoPath, revision string) (string, error) {
+	tag, err := runGit(ctx, repoPath, "describe", "--tags", "--abbrev=0", revision)
+	if err != nil {
+		if strings.Contains(err.Error(), "No names found") || strings.Contains(err.Error(), "No tags can describe") {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(tag), nil
+}
+
+// collectGitLog returns the non-merge commits in from..to, newest first; an empty from lists all of
+// to's history.
+func collectGitLog(ctx context.Context, repoPath, from, to string) ([]Commit, error) {
+	from, to, err := gitRange(ctx, repoPath, from, to)
+	if err != nil {
+		return nil, err
+	}
+	revision := to
+	if from != "" {
+		revision = from + ".." + to
+	}
+	out, err := runGit(ctx, repoPath, "log", "--no-color", "--no-merges", "--format="+gitLogFormat, revision)
+	if err != nil {
+		return nil, err
+	}
+	return parseGitLog(out), nil
+}
+
+func parseGitLog(out string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(out, gitRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, gitFieldSeparator, 6)
+		if len(fields) < 6 {
+			continue
+		}
+		trailers := parseTrailers(fields[5])
+		commits = append(commits, Commit{
+			SHA:      fields[0],
+			Author:   fields[1],
+			Email:    fields[2],
+			Subject:  fields[3],
+			Body:     bodyWithoutTrailers(fields[4], trailers),
+			Trailers: trailers,
+		})
+	}
+	return commits
+}
+
+func parseTrailers(block string) []Trailer {
+	var trailers []Trailer
+	for _, line := range strings.Split(block, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		trailers = append(trailers, Trailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
+
+// bodyWithoutTrailers drops the trailer paragraph from %b, since trailers are reported on their own.
+func bodyWithoutTrailers(body string, trailers []Trailer) string {
+	body = strings.TrimSpace(body)
+	if len(trailers) == 0 {
+		return body
+	}
+	paragraphs := strings.Split(body, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	for _, trailer := range trailers {
+		if !strings.Contains(last, trailer.Key+":") {
+			return body
+		}
+	}
+	return strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"))
+}
+
+// formatCommits renders the collected log as indented JSON for the prompt.
+func formatCommits(commits []Commit) (string, error) {
+	if len(commits) == 0 {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(commits, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func runGit(ctx context.Context, repoPath string, args ...string) (string, error) {
+	command := exec.CommandContext(ctx, "git", append([]string{"-C", coalesce(repoPath, ".")}, args...)...)
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,118 @@
+package changelog_test
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	changelog "github.com/temirov/llm-tasks/tasks/changelog"
+)
+
+func git(t *testing.T, repo string, args ...string) {
+	t.Helper()
+	command := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=Ada", "-c", "user.email=ada@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+	if out, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+	git(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: initial import")
+	git(t, repo, "tag", "v0.1.0")
+	git(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: add widgets\n\nWidgets can be stacked.\n\nCo-authored-by: Bob <bob@example.com>\nRefs: #42")
+	git(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: widget overflow")
+	return repo
+}
+
+func gatherGitLog(t *testing.T, repo, from string) []changelog.Commit {
+	t.Helper()
+	cfg := strings.Replace(cfgYAML, "git_log: { required: true, source: stdin }",
+		`git_log: { required: true, source: git, repo_path: "`+repo+`", from: "`+from+`" }`, 1)
+	task, err := changelog.NewFromYAML(withTempFile(t, "task.changelog.yaml", cfg))
+	if err != nil {
+		t.Fatalf("NewFromYAML: %v", err)
+	}
+	setEnv(t, "CHANGELOG_VERSION", "0.2.0")
+	setEnv(t, "CHANGELOG_DATE", "2025-03-01")
+
+	gathered, err := task.Gather(context.Background())
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	var commits []changelog.Commit
+	if err := json.Unmarshal([]byte(gathered.(map[string]string)["git_log"]), &commits); err != nil {
+		t.Fatalf("git_log is not structured JSON: %v", err)
+	}
+	return commits
+}
+
+func TestChangelog_GitSource_DefaultsToLastTag(t *testing.T) {
+	repo := newGitRepo(t)
+	commits := gatherGitLog(t, repo, "")
+	if len(commits) != 2 {
+		t.Fatalf("expected the 2 commits since v0.1.0, got %+v", commits)
+	}
+	if commits[0].Subject != "fix: widget overflow" {
+		t.Fatalf("expected newest commit first, got %q", commits[0].Subject)
+	}
+
+	feature := commits[1]
+	if feature.Author != "Ada" || feature.Email != "ada@example.com" || len(feature.SHA) != 40 {
+		t.Fatalf("unexpected commit metadata: %+v", feature)
+	}
+	if feature.Body != "Widgets can be stacked." {
+		t.Fatalf("expected trailers to be split from the body, got %q", feature.Body)
+	}
+	expectedTrailers := []changelog.Trailer{
+		{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+		{Key: "Refs", Value: "#42"},
+	}
+	if len(feature.Trailers) != len(expectedTrailers) {
+		t.Fatalf("expected trailers %+v, got %+v", expectedTrailers, feature.Trailers)
+	}
+	for i, trailer := range expectedTrailers {
+		if feature.Trailers[i] != trailer {
+			t.Fatalf("expected trailer %+v, got %+v", trailer, feature.Trailers[i])
+		}
+	}
+}
+
+func TestChangelog_GitSource_ExplicitFromAndUntaggedRepo(t *testing.T) {
+	repo := newGitRepo(t)
+	if commits := gatherGitLog(t, repo, "HEAD~1"); len(commits) != 1 {
+		t.Fatalf("expected 1 commit in HEAD~1..HEAD, got %+v", commits)
+	}
+
+	untagged := filepath.Join(t.TempDir(), "untagged")
+	git(t, filepath.Dir(untagged), "init", "-q", untagged)
+	git(t, untagged, "commit", "-q", "--allow-empty", "-m", "first")
+	git(t, untagged, "commit", "-q", "--allow-empty", "-m", "second")
+	if commits := gatherGitLog(t, untagged, ""); len(commits) != 2 {
+		t.Fatalf("expected the whole history without tags, got %+v", commits)
+	}
+}
+
+func TestChangelog_GitSource_TaggedHeadUsesPreviousTag(t *testing.T) {
+	repo := newGitRepo(t)
+	git(t, repo, "tag", "v0.2.0")
+	if commits := gatherGitLog(t, repo, ""); len(commits) != 2 {
+		t.Fatalf("expected the 2 commits between v0.1.0 and the tagged HEAD, got %+v", commits)
+	}
+
+	single := filepath.Join(t.TempDir(), "single")
+	git(t, filepath.Dir(single), "init", "-q", single)
+	git(t, single, "commit", "-q", "--allow-empty", "-m", "first")
+	git(t, single, "tag", "v1.0.0")
+	if commits := gatherGitLog(t, single, ""); len(commits) != 1 {
+		t.Fatalf("expected the tagged root commit, got %+v", commits)
+	}
+}
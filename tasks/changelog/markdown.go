@@ -0,0 +1,180 @@
+package changelog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const sectionHeadingPrefix = "### "
+
+// parsedChangelog is the structure of a generated section: the release heading, the "###"
+// sections in order, any other headings, and the footer when it was found. Unplaced holds
+// the text a Release cannot carry, so Verify can reject it instead of dropping it.
+type parsedChangelog struct {
+	Heading       string
+	Sections      []ReleaseSection
+	OtherHeadings []string
+	Unplaced      []unplacedText
+	HasFooter     bool
+	Footer        string
+}
+
+// unplacedText is a line outside any section (Section is empty) or a note after a section's bullets.
+type unplacedText struct {
+	Section string
+	Text    string
+}
+
+// parseChangelog splits md into sections. A line starting with footer ends the last section;
+// it and everything after it, such as listed breaking changes, form the footer.
+func parseChangelog(md, footer string) parsedChangelog {
+	var parsed parsedChangelog
+	footer = firstLine(footer)
+	var (
+		current     *ReleaseSection
+		footerLines []string
+	)
+	for index, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case index == 0:
+			parsed.Heading = trimmed
+		case parsed.HasFooter:
+			footerLines = append(footerLines, line)
+		case footer != "" && strings.HasPrefix(trimmed, footer):
+			parsed.HasFooter = true
+			footerLines = append(footerLines, line)
+			current = nil
+		case strings.HasPrefix(trimmed, sectionHeadingPrefix):
+			parsed.Sections = append(parsed.Sections, ReleaseSection{Title: strings.TrimSpace(strings.TrimPrefix(trimmed, sectionHeadingPrefix))})
+			current = &parsed.Sections[len(parsed.Sections)-1]
+		case strings.HasPrefix(trimmed, "#"):
+			parsed.OtherHeadings = append(parsed.OtherHeadings, trimmed)
+			current = nil
+		case trimmed == "":
+		case current == nil:
+			parsed.Unplaced = append(parsed.Unplaced, unplacedText{Text: trimmed})
+		case isTopLevelBullet(line):
+			current.Bullets = append(current.Bullets, strings.TrimSpace(trimmed[2:]))
+		case len(current.Bullets) > 0 && line != trimmed:
+			// an indented line continues the last bullet, e.g. a nested list
+			current.Bullets[len(current.Bullets)-1] += "\n" + strings.TrimRight(line, " \t")
+		case len(current.Bullets) > 0:
+			parsed.Unplaced = append(parsed.Unplaced, unplacedText{Section: current.Title, Text: trimmed})
+		default:
+			current.Notes = append(current.Notes, trimmed)
+		}
+	}
+	parsed.Footer = strings.TrimSpace(strings.Join(footerLines, "\n"))
+	return parsed
+}
+
+func (p parsedChangelog) section(title string) (ReleaseSection, bool) {
+	for _, section := range p.Sections {
+		if section.Title == title {
+			return section, true
+		}
+	}
+	return ReleaseSection{}, false
+}
+
+func isTopLevelBullet(line string) bool {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	trimmed := strings.TrimSpace(line)
+	return indent < 2 && (strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// structureViolation is one way the generated section breaks the configured format.
+type structureViolation struct {
+	Reason  string
+	Message string
+}
+
+// structureViolations checks every configured section for presence and its min/max bullet
+// limits, rejects sections, headings and text the format does not define, and requires the footer.
+func (t *Task) structureViolations(parsed parsedChangelog) []structureViolation {
+	footer := firstLine(t.footer())
+	var violations []structureViolation
+	known := map[string]bool{}
+	for _, configured := range t.cfg.Recipe.Format.Sections {
+		known[configured.Title] = true
+		section, found := parsed.section(configured.Title)
+		switch {
+		case !found:
+			violations = append(violations, structureViolation{
+				Reason:  "missing-section",
+				Message: fmt.Sprintf("Include the section heading %q exactly, even if empty.", sectionHeadingPrefix+configured.Title),
+			})
+		case configured.Min > 0 && len(section.Bullets) < configured.Min:
+			violations = append(violations, structureViolation{
+				Reason:  "too-few-bullets",
+				Message: fmt.Sprintf("Provide at least %d concise bullets under %q (found %d).", configured.Min, configured.Title, len(section.Bullets)),
+			})
+		case configured.Max > 0 && len(section.Bullets) > configured.Max:
+			violations = append(violations, structureViolation{
+				Reason:  "too-many-bullets",
+				Message: fmt.Sprintf("Keep at most %d bullets under %q (found %d); merge or drop the least important.", configured.Max, configured.Title, len(section.Bullets)),
+			})
+		}
+	}
+	for _, section := range parsed.Sections {
+		if !known[section.Title] {
+			violations = append(violations, structureViolation{
+				Reason:  "unknown-section",
+				Message: fmt.Sprintf("Remove the section %q; use only the configured sections.", sectionHeadingPrefix+section.Title),
+			})
+		}
+	}
+	for _, heading := range parsed.OtherHeadings {
+		violations = append(violations, structureViolation{
+			Reason:  "unknown-section",
+			Message: fmt.Sprintf("Remove the heading %q; use only the configured sections.", heading),
+		})
+	}
+	for _, unplaced := range parsed.Unplaced {
+		if footer != "" && !parsed.HasFooter {
+			// a wrong or misspelt footer is unplaced text too; the footer violation covers it
+			break
+		}
+		message := fmt.Sprintf("Remove the text %q outside the sections, or move it into one.", unplaced.Text)
+		if unplaced.Section != "" {
+			message = fmt.Sprintf("Move the text %q under %q above its bullets, or make it a bullet.", unplaced.Text, sectionHeadingPrefix+unplaced.Section)
+		}
+		violations = append(violations, structureViolation{Reason: "unplaced-text", Message: message})
+	}
+	if footer != "" && !parsed.HasFooter {
+		violation := structureViolation{Reason: "missing-footer", Message: fmt.Sprintf("End with the footer %q exactly.", footer)}
+		if hasBreaking(t.commits) {
+			violation = structureViolation{
+				Reason:  "missing-breaking-footer",
+				Message: fmt.Sprintf("The release has breaking changes; end with the footer %q exactly and list them under it.", footer),
+			}
+		}
+		violations = append(violations, violation)
+	}
+	return violations
+}
+
+// structureRefine folds the violations into one refine request with one line per violation.
+func structureRefine(violations []structureViolation) *pipeline.RefineRequest {
+	messages := make([]string, 0, len(violations))
+	var reasons []string
+	for _, violation := range violations {
+		messages = append(messages, "- "+violation.Message)
+		if !slices.Contains(reasons, violation.Reason) {
+			reasons = append(reasons, violation.Reason)
+		}
+	}
+	return &pipeline.RefineRequest{
+		UserPromptDelta: "Fix the section structure:\n" + strings.Join(messages, "\n"),
+		Reason:          strings.Join(reasons, ","),
+	}
+}
@@ -0,0 +1,133 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const limitsConfigYAML = `
+task: changelog
+recipe:
+  format:
+    heading: "## [${version}]"
+    sections:
+      - { title: "Highlights", min: 1, max: 2 }
+      - { title: "Features", max: 3 }
+      - { title: "Fixes" }
+    footer: "**Upgrade notes:** No breaking changes."
+`
+
+func TestParseChangelogCollectsTopLevelBullets(t *testing.T) {
+	md := "## [1.0.0]\n\n### Highlights\n\n- One\n  - nested detail\n* Two\n\n### Fixes\n\n+ Three\n\n**Upgrade notes:** Breaking.\n- listed under the footer\n"
+	parsed := parseChangelog(md, "**Upgrade notes:**")
+
+	if !parsed.HasFooter {
+		t.Fatalf("expected the footer to be found")
+	}
+	highlights, _ := parsed.section("Highlights")
+	fixes, _ := parsed.section("Fixes")
+	if len(highlights.Bullets) != 2 || len(fixes.Bullets) != 1 {
+		t.Fatalf("expected 2 highlights and 1 fix, got %+v", parsed.Sections)
+	}
+	if highlights.Bullets[0] != "One\n  - nested detail" {
+		t.Fatalf("expected the nested item to stay with its bullet, got %q", highlights.Bullets[0])
+	}
+	if parsed.Footer != "**Upgrade notes:** Breaking.\n- listed under the footer" {
+		t.Fatalf("unexpected footer %q", parsed.Footer)
+	}
+}
+
+func TestVerifyEnforcesSectionStructure(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(limitsConfigYAML), &cfg); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	task := NewFromConfig(cfg)
+	task.version = "1.0.0"
+
+	const footer = "\n**Upgrade notes:** No breaking changes.\n"
+	testCases := []struct {
+		name             string
+		md               string
+		expectedReason   string
+		expectedMessages []string
+	}{
+		{
+			name:           "too few highlights",
+			md:             "## [1.0.0]\n\n### Highlights\n\n### Features\n\n### Fixes\n" + footer,
+			expectedReason: "too-few-bullets",
+		},
+		{
+			name:           "too many features",
+			md:             "## [1.0.0]\n\n### Highlights\n\n- A\n\n### Features\n\n- 1\n- 2\n- 3\n- 4\n\n### Fixes\n" + footer,
+			expectedReason: "too-many-bullets",
+		},
+		{
+			name:           "unknown section",
+			md:             "## [1.0.0]\n\n### Highlights\n\n- A\n\n### Features\n\n### Fixes\n\n### Security\n\n- B\n" + footer,
+			expectedReason: "unknown-section",
+		},
+		{
+			name:           "missing footer",
+			md:             "## [1.0.0]\n\n### Highlights\n\n- A\n\n### Features\n\n### Fixes\n",
+			expectedReason: "missing-footer",
+		},
+		{
+			name:           "every violation reported",
+			md:             "## [1.0.0]\n\n### Highlights\n\n- A\n- B\n- C\n\n### Extras\n\n## [0.9.0]\n",
+			expectedReason: "too-many-bullets,missing-section,unknown-section,missing-footer",
+			expectedMessages: []string{
+				`Keep at most 2 bullets under "Highlights"`,
+				`"### Features"`,
+				`"### Fixes"`,
+				`Remove the section "### Extras"`,
+				`Remove the heading "## [0.9.0]"`,
+				`End with the footer`,
+			},
+		},
+		{
+			name:           "text outside sections or after bullets",
+			md:             "## [1.0.0]\n\nA quieter release.\n\n### Highlights\n\nRead this first.\n\n- A\n\nAlso worth noting.\n\n### Features\n\n### Fixes\n" + footer,
+			expectedReason: "unplaced-text",
+			expectedMessages: []string{
+				`Remove the text "A quieter release." outside the sections`,
+				`Move the text "Also worth noting." under "### Highlights" above its bullets`,
+			},
+		},
+		{
+			name: "notes before the bullets",
+			md:   "## [1.0.0]\n\n### Highlights\n\nRead this first.\n\n- A\n\n### Features\n\n### Fixes\n" + footer,
+		},
+		{
+			name: "valid",
+			md:   "## [1.0.0]\n\n### Highlights\n\n- A\n\n### Features\n\n- 1\n- 2\n\n### Fixes\n" + footer,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, _, refine, err := task.Verify(context.Background(), nil, pipeline.LLMResponse{RawText: testCase.md})
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if testCase.expectedReason == "" {
+				if !ok {
+					t.Fatalf("expected acceptance, got refine %+v", refine)
+				}
+				return
+			}
+			if ok || refine == nil || refine.Reason != testCase.expectedReason {
+				t.Fatalf("expected refine %q, got ok=%v refine=%+v", testCase.expectedReason, ok, refine)
+			}
+			for _, message := range testCase.expectedMessages {
+				if !strings.Contains(refine.UserPromptDelta, message) {
+					t.Fatalf("expected %q in refine message:\n%s", message, refine.UserPromptDelta)
+				}
+			}
+		})
+	}
+}
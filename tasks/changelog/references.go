@@ -0,0 +1,97 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	pullRequestPattern = regexp.MustCompile(`#(\d+)\b`)
+	// shaCandidatePattern matches hex words; a candidate counts as a SHA only if it mixes digits
+	// and letters, so dates, years and words such as "deadbeef" or "defaced" are not mistaken for one.
+	shaCandidatePattern = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+)
+
+// logReferences indexes the PR numbers and commit SHAs present in the gathered git log.
+type logReferences struct {
+	pullRequests map[string]bool
+	shas         []string
+}
+
+func newLogReferences(gitLog string) logReferences {
+	refs := logReferences{pullRequests: map[string]bool{}}
+	for _, match := range pullRequestPattern.FindAllStringSubmatch(gitLog, -1) {
+		refs.pullRequests[match[1]] = true
+	}
+	refs.shas = shaCandidatePattern.FindAllString(strings.ToLower(gitLog), -1)
+	return refs
+}
+
+// hasSHA accepts a cited SHA that is a prefix of a logged one or the other way round, since
+// the log and the output may abbreviate to different lengths.
+func (r logReferences) hasSHA(cited string) bool {
+	for _, sha := range r.shas {
+		if strings.HasPrefix(sha, cited) || strings.HasPrefix(cited, sha) {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownReferences returns the PR numbers and SHAs cited in md that do not occur in the git log.
+func unknownReferences(md, gitLog string) []string {
+	refs := newLogReferences(gitLog)
+	var unknown []string
+	seen := map[string]bool{}
+	for _, match := range pullRequestPattern.FindAllStringSubmatch(md, -1) {
+		if !refs.pullRequests[match[1]] && !seen[match[0]] {
+			seen[match[0]] = true
+			unknown = append(unknown, match[0])
+		}
+	}
+	for _, candidate := range shaCandidatePattern.FindAllString(md, -1) {
+		if !looksLikeSHA(candidate) || refs.hasSHA(candidate) || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		unknown = append(unknown, candidate)
+	}
+	return unknown
+}
+
+// omittedCommits lists the gathered commits that belong in the release but are cited nowhere
+// in md, neither by short SHA nor by the number of the pull request they came from.
+func omittedCommits(md, gitLog string, cfg Config) []conventionalCommit {
+	cited := map[string]bool{}
+	for _, match := range pullRequestPattern.FindAllStringSubmatch(md, -1) {
+		cited[match[1]] = true
+	}
+	var omitted []conventionalCommit
+	for _, commit := range parseGatheredCommits(gitLog) {
+		if commit.SHA == "" || !belongsInRelease(commit, cfg) || strings.Contains(md, commit.shortSHA()) || cited[commit.PullRequest] {
+			continue
+		}
+		omitted = append(omitted, commit)
+	}
+	return omitted
+}
+
+// belongsInRelease reports whether commit must appear in the release: with recipe.mapping,
+// when its type maps to a configured section; without, when it is a feat or fix. Trivial
+// commits (chore, ci, docs, …) may be summarized or left out to respect the bullet limits.
+func belongsInRelease(commit conventionalCommit, cfg Config) bool {
+	if len(cfg.Recipe.Mapping) == 0 {
+		return citedCommitTypes[commit.Type]
+	}
+	title := strings.TrimSpace(cfg.Recipe.Mapping[commit.Type])
+	for _, section := range cfg.Recipe.Format.Sections {
+		if title != "" && section.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeSHA(candidate string) bool {
+	return strings.ContainsAny(candidate, "0123456789") && strings.ContainsAny(candidate, "abcdef")
+}
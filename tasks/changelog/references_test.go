@@ -0,0 +1,110 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+func TestUnknownReferences(t *testing.T) {
+	gitLog := "1a2b3c4d5e6f7a8b feat: widgets (#12)\n9f8e7d6 fix: overflow (#1234)\n"
+	testCases := []struct {
+		name     string
+		md       string
+		expected []string
+	}{
+		{name: "known references", md: "- Widgets (#12, 1a2b3c4)\n- Overflow (#1234, 9f8e7d6c)\n"},
+		{name: "invented pull request", md: "- Widgets (#12)\n- Speedups (#123)\n", expected: []string{"#123"}},
+		{name: "invented sha", md: "- Widgets (1a2b3c4)\n- Cache (abc1234)\n", expected: []string{"abc1234"}},
+		{name: "dates and hex words are not shas", md: "## [1.0.0] - 20250301\n- A deadbeef facade, defaced\n"},
+		{name: "duplicates reported once", md: "- A (#7)\n- B (#7)\n", expected: []string{"#7"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := unknownReferences(testCase.md, gitLog)
+			if strings.Join(got, ",") != strings.Join(testCase.expected, ",") {
+				t.Fatalf("expected %v, got %v", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsHallucinatedAndOmittedCommits(t *testing.T) {
+	task := NewFromConfig(mappingConfig(t))
+	task.cfg.Recipe.Mapping = nil
+	task.version = "1.1.0"
+	task.gitLog = "abc1234 feat: add widgets (#12)\ndef5678 fix: typo\n9999999 chore: tidy\n"
+
+	header := "## [1.1.0]\n\n### Features\n\n### Fixes\n\n### Maintenance\n\n"
+	footer := "\n**Upgrade notes:** No breaking changes.\n"
+	testCases := []struct {
+		name           string
+		md             string
+		expectedReason string
+		expectedText   string
+	}{
+		{name: "invented pull request", md: header + "- Widgets (#13, abc1234)\n- Typo (def5678)\n" + footer, expectedReason: "unknown-references", expectedText: "#13"},
+		{name: "omitted commit", md: header + "- Widgets (#12, abc1234)\n" + footer, expectedReason: "omitted-commits", expectedText: "def5678 fix: typo"},
+		{name: "complete without the chore", md: header + "- Widgets (#12, abc1234)\n- Typo (def5678)\n" + footer},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, _, refine, err := task.Verify(context.Background(), nil, pipeline.LLMResponse{RawText: testCase.md})
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if testCase.expectedReason == "" {
+				if !ok {
+					t.Fatalf("expected acceptance, got refine %+v", refine)
+				}
+				return
+			}
+			if ok || refine == nil || refine.Reason != testCase.expectedReason || !strings.Contains(refine.UserPromptDelta, testCase.expectedText) {
+				t.Fatalf("expected refine %q mentioning %q, got ok=%v refine=%+v", testCase.expectedReason, testCase.expectedText, ok, refine)
+			}
+		})
+	}
+}
+
+func TestOmittedCommitsSkipsMergesAndAcceptsPullRequestCitations(t *testing.T) {
+	testCases := []struct {
+		name   string
+		gitLog string
+	}{
+		{
+			name:   "oneline",
+			gitLog: "1111111 Merge pull request #12 from ada/widgets\nabc1234 feat: add widgets (#12)\ndef5678 fix: typo\n",
+		},
+		{
+			name: "full git log",
+			gitLog: "commit 1111111111111111111111111111111111111111\nMerge: abc1234 def5678\nAuthor: Ada <ada@example.com>\n\n    Release widgets\n\n" +
+				"commit abc1234abc1234abc1234abc1234abc1234abc12\nAuthor: Ada <ada@example.com>\n\n    feat: add widgets (#12)\n\n" +
+				"commit def5678def5678def5678def5678def5678def56\nAuthor: Ada <ada@example.com>\n\n    fix: typo\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			omitted := omittedCommits("- Widgets (#12)\n", testCase.gitLog, Config{})
+			if len(omitted) != 1 || omitted[0].Subject != "typo" {
+				t.Fatalf("expected only the uncited fix to be omitted, got %+v", omitted)
+			}
+		})
+	}
+}
+
+func TestOmittedCommitsOnlyRequiresMappedSections(t *testing.T) {
+	cfg := mappingConfig(t)
+	cfg.Recipe.Mapping["docs"] = "Documentation" // no such section in the format
+	gitLog := "abc1234 feat: widgets\ndef5678 chore: bump deps\n1234567 docs: typo\n"
+
+	omitted := omittedCommits("- Widgets (abc1234)\n", gitLog, cfg)
+	if len(omitted) != 1 || omitted[0].Type != "chore" {
+		t.Fatalf("expected only the chore mapped to Maintenance to be required, got %+v", omitted)
+	}
+	delete(cfg.Recipe.Mapping, "chore")
+	if omitted := omittedCommits("- Widgets (abc1234)\n", gitLog, cfg); len(omitted) != 0 {
+		t.Fatalf("expected unmapped commits to be optional, got %+v", omitted)
+	}
+}
@@ -0,0 +1,72 @@
+package changelog
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Release is the verified changelog section. Verify builds it from the model's Markdown and
+// every apply mode renders its output from it.
+type Release struct {
+	Version  string           `json:"version"`
+	Date     string           `json:"date"`
+	Heading  string           `json:"heading"`
+	Sections []ReleaseSection `json:"sections"`
+	Footer   string           `json:"footer,omitempty"`
+}
+
+// ReleaseSection is one "###" section; a bullet keeps its nested lines after a newline.
+type ReleaseSection struct {
+	Title   string   `json:"title"`
+	Notes   []string `json:"notes,omitempty"`
+	Bullets []string `json:"bullets"`
+}
+
+// Markdown renders the release as a CHANGELOG.md section, starting with its heading.
+func (r Release) Markdown() string {
+	return r.Heading + "\n\n" + r.body()
+}
+
+// GitHubBody renders the release notes for a GitHub release, whose title carries the version.
+func (r Release) GitHubBody() string {
+	return r.body() + "\n"
+}
+
+// JSON renders the release for release tooling.
+func (r Release) JSON() (string, error) {
+	for index := range r.Sections {
+		if r.Sections[index].Bullets == nil {
+			r.Sections[index].Bullets = []string{}
+		}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func (r Release) body() string {
+	blocks := make([]string, 0, len(r.Sections)+1)
+	for _, section := range r.Sections {
+		var block strings.Builder
+		block.WriteString(sectionHeadingPrefix)
+		block.WriteString(section.Title)
+		for _, note := range section.Notes {
+			block.WriteString("\n\n")
+			block.WriteString(note)
+		}
+		if len(section.Bullets) > 0 {
+			block.WriteString("\n")
+		}
+		for _, bullet := range section.Bullets {
+			block.WriteString("\n- ")
+			block.WriteString(bullet)
+		}
+		blocks = append(blocks, block.String())
+	}
+	if r.Footer != "" {
+		blocks = append(blocks, r.Footer)
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const releaseMarkdown = "## [v1.2.0] - 2025-03-01\n\n### Highlights\n\n- Widgets (#12)\n  - stackable\n\n### Fixes\n\n### Docs\n\nSee the new guide.\n\n- Config reference\n\n**Upgrade notes:** No breaking changes."
+
+func sampleRelease() Release {
+	parsed := parseChangelog(releaseMarkdown, "**Upgrade notes:**")
+	return Release{Version: "v1.2.0", Date: "2025-03-01", Heading: parsed.Heading, Sections: parsed.Sections, Footer: parsed.Footer}
+}
+
+func TestReleaseRenderers(t *testing.T) {
+	release := sampleRelease()
+
+	if got := release.Markdown(); got != releaseMarkdown {
+		t.Fatalf("expected Markdown to round-trip\nexpected:\n%q\ngot:\n%q", releaseMarkdown, got)
+	}
+	if got := release.GitHubBody(); !strings.HasPrefix(got, "### Highlights\n") || strings.Contains(got, "## [v1.2.0]") {
+		t.Fatalf("expected a GitHub body without the version heading, got:\n%s", got)
+	}
+
+	out, err := release.JSON()
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var decoded Release
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(decoded.Sections) != 3 || decoded.Sections[0].Bullets[0] != "Widgets (#12)\n  - stackable" || decoded.Sections[2].Notes[0] != "See the new guide." {
+		t.Fatalf("unexpected JSON release: %s", out)
+	}
+	if !strings.Contains(out, `"bullets": []`) {
+		t.Fatalf("expected empty sections to carry an empty bullet list: %s", out)
+	}
+}
+
+func TestDebianEntry(t *testing.T) {
+	var cfg Config
+	cfg.Apply.Debian.Package = "llm-tasks"
+	cfg.Apply.Debian.Maintainer = "Ada <ada@example.com>"
+	task := NewFromConfig(cfg)
+
+	entry, err := task.debianEntry(sampleRelease(), time.Now())
+	if err != nil {
+		t.Fatalf("debian entry: %v", err)
+	}
+	expected := "llm-tasks (1.2.0) unstable; urgency=medium\n\n" +
+		"  * Highlights:\n    - Widgets (#12)\n      - stackable\n" +
+		"  * Docs:\n    - Config reference\n" +
+		"  **Upgrade notes:** No breaking changes.\n\n" +
+		" -- Ada <ada@example.com>  Sat, 01 Mar 2025 00:00:00 +0000\n"
+	if entry != expected {
+		t.Fatalf("unexpected entry\nexpected:\n%s\ngot:\n%s", expected, entry)
+	}
+
+	const older = "llm-tasks (1.1.0) unstable; urgency=medium\n\n  * Old\n\n -- Ada <ada@example.com>  Sat, 01 Feb 2025 00:00:00 +0000\n"
+	updated, action := upsertDebianEntry(older, entry, "v1.2.0")
+	if action != actionPrepended || updated != entry+"\n"+older {
+		t.Fatalf("expected the entry on top, got %+v:\n%s", action, updated)
+	}
+	again, action := upsertDebianEntry(updated, entry, "v1.2.0")
+	if action != actionReplaced || again != updated {
+		t.Fatalf("expected re-running to replace the entry in place, got %+v:\n%s", action, again)
+	}
+
+	task.cfg.Apply.Debian.Maintainer = ""
+	t.Setenv("DEBFULLNAME", "")
+	if _, err := task.debianEntry(sampleRelease(), time.Now()); err == nil {
+		t.Fatalf("expected a missing maintainer to be an error")
+	}
+}
+
+func TestApplyWritesJSONRelease(t *testing.T) {
+	var cfg Config
+	cfg.Apply.Mode = "json"
+	cfg.Apply.OutputPath = filepath.Join(t.TempDir(), "release", "notes.json")
+	task := NewFromConfig(cfg)
+
+	report, err := task.Apply(context.Background(), sampleRelease())
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if report.Summary != "wrote changelog to "+cfg.Apply.OutputPath {
+		t.Fatalf("unexpected summary %q", report.Summary)
+	}
+	data, err := os.ReadFile(cfg.Apply.OutputPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), `"version": "v1.2.0"`) {
+		t.Fatalf("unexpected JSON file:\n%s", data)
+	}
+}
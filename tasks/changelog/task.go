@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 
 	"github.com/temirov/llm-tasks/internal/config"
@@ -25,8 +26,10 @@ type Task struct {
 	version string
 	date    string
 	gitLog  string
+	// commits holds the parsed Conventional Commits when recipe.mapping is configured.
+	commits []conventionalCommit
 	request pipeline.LLMRequest
-	section string
+	release Release
 }
 
 // New provides a zero-arg factory for CLI registry.
@@ -61,7 +64,7 @@ func NewFromYAML(yamlPath string) (*Task, error) {
 
 func (t *Task) Name() string { return "changelog" }
 
-// 1) Gather: version, date, git log (stdin)
+// 1) Gather: version, date, git log (stdin or collected from git)
 func (t *Task) Gather(ctx context.Context) (pipeline.GatherOutput, error) {
 	v := coalesce(os.Getenv(t.cfg.Inputs.Version.Env), t.cfg.Inputs.Version.Default)
 	d := coalesce(os.Getenv(t.cfg.Inputs.Date.Env), t.cfg.Inputs.Date.Default)
@@ -74,18 +77,44 @@ func (t *Task) Gather(ctx context.Context) (pipeline.GatherOutput, error) {
 	}
 
 	var gl string
-	if strings.EqualFold(t.cfg.Inputs.GitLog.Source, "stdin") {
+	source := t.cfg.Inputs.GitLog.Source
+	switch {
+	case strings.EqualFold(source, gitLogSourceStdin):
 		var buf bytes.Buffer
 		if err := readAllToBufferCtx(ctx, os.Stdin, &buf); err != nil {
 			return nil, fmt.Errorf("reading stdin: %w", err)
 		}
 		gl = strings.TrimSpace(buf.String())
+	case strings.EqualFold(source, gitLogSourceGit):
+		gitLog := t.cfg.Inputs.GitLog
+		commits, err := collectGitLog(ctx, gitLog.RepoPath, gitLog.From, gitLog.To)
+		if err != nil {
+			return nil, fmt.Errorf("collect git log: %w", err)
+		}
+		if gl, err = formatCommits(commits); err != nil {
+			return nil, fmt.Errorf("format git log: %w", err)
+		}
 	}
 	if t.cfg.Inputs.GitLog.Required && gl == "" {
+		if strings.EqualFold(source, gitLogSourceGit) {
+			return nil, errors.New("git_log is required but the git range has no commits")
+		}
 		return nil, errors.New("git_log is required on stdin")
 	}
 
 	t.version, t.date, t.gitLog = v, d, gl
+	t.commits = nil
+	if len(t.cfg.Recipe.Mapping) > 0 {
+		t.commits = parseGatheredCommits(gl)
+	}
+	pipeline.LoggerFromContext(ctx).Info("changelog inputs",
+		zap.String("version", v),
+		zap.String("date", d),
+		zap.String("git_log_source", t.cfg.Inputs.GitLog.Source),
+		zap.Int("git_log_lines", countLines(gl)),
+		zap.Int("conventional_commits", len(t.commits)),
+		zap.Bool("breaking", hasBreaking(t.commits)),
+	)
 	return map[string]string{"version": v, "date": d, "git_log": gl}, nil
 }
 
@@ -107,7 +136,7 @@ func (t *Task) Prompt(ctx context.Context, _ pipeline.GatherOutput) (pipeline.LL
 		sb.WriteString(s.Title)
 		sb.WriteString("\n\n")
 	}
-	if foot := strings.TrimSpace(t.cfg.Recipe.Format.Footer); foot != "" {
+	if foot := t.footer(); foot != "" {
 		sb.WriteString(foot)
 		sb.WriteString("\n\n")
 	}
@@ -117,6 +146,22 @@ func (t *Task) Prompt(ctx context.Context, _ pipeline.GatherOutput) (pipeline.LL
 		sb.WriteString(r)
 		sb.WriteString("\n")
 	}
+	if t.commits != nil {
+		sb.WriteString("- Cite every feat and fix commit by its short SHA.\n")
+		if hasBreaking(t.commits) {
+			fmt.Fprintf(&sb, "- This release has breaking changes: end with the footer %q and list each breaking change under it.\n", t.footer())
+		}
+		sb.WriteString("\nCommits pre-grouped by Conventional Commit type:\n")
+		for _, section := range groupCommits(t.commits, t.cfg) {
+			sb.WriteString("### ")
+			sb.WriteString(section.Title)
+			sb.WriteString("\n")
+			for _, commit := range section.Commits {
+				sb.WriteString(formatCommitLine(commit))
+				sb.WriteString("\n")
+			}
+		}
+	}
 	sb.WriteString("\nGit log:\n")
 	sb.WriteString(t.gitLog)
 
@@ -154,64 +199,93 @@ func (t *Task) Verify(ctx context.Context, _ pipeline.GatherOutput, response pip
 		}, nil
 	}
 
-	// Ensure each configured section title exists
-	for _, s := range t.cfg.Recipe.Format.Sections {
-		needle := "### " + s.Title
-		if !strings.Contains(md, needle) {
-			return false, nil, &pipeline.RefineRequest{
-				UserPromptDelta: fmt.Sprintf("Include the section heading %q exactly, even if empty.", needle),
-				Reason:          "missing-section",
-			}, nil
-		}
+	parsed := parseChangelog(md, t.footer())
+	if violations := t.structureViolations(parsed); len(violations) > 0 {
+		return false, nil, structureRefine(violations), nil
 	}
 
-	// Highlights min constraint (if > 0)
-	if len(t.cfg.Recipe.Format.Sections) > 0 && t.cfg.Recipe.Format.Sections[0].Title == "Highlights" && t.cfg.Recipe.Format.Sections[0].Min > 0 {
-		highlightsBlock := extractSection(md, "Highlights")
-		bullets := countBullets(highlightsBlock)
-		if bullets < t.cfg.Recipe.Format.Sections[0].Min {
-			return false, nil, &pipeline.RefineRequest{
-				UserPromptDelta: fmt.Sprintf("Provide at least %d concise bullets under 'Highlights'.", t.cfg.Recipe.Format.Sections[0].Min),
-				Reason:          "too-few-highlights",
-			}, nil
+	if unknown := unknownReferences(md, t.gitLog); len(unknown) > 0 {
+		return false, nil, &pipeline.RefineRequest{
+			UserPromptDelta: fmt.Sprintf("These references do not appear in the git log: %s. Remove them; cite only PR numbers and SHAs from the git log.", strings.Join(unknown, ", ")),
+			Reason:          "unknown-references",
+		}, nil
+	}
+	if missing := uncitedCommits(t.commits, md); len(missing) > 0 {
+		lines := make([]string, 0, len(missing))
+		for _, commit := range missing {
+			lines = append(lines, formatCommitLine(commit))
 		}
+		return false, nil, &pipeline.RefineRequest{
+			UserPromptDelta: "Cite each of these commits by its short SHA:\n" + strings.Join(lines, "\n"),
+			Reason:          "uncited-commits",
+		}, nil
 	}
-
-	t.section = md
-	return true, md, nil, nil
+	if omitted := omittedCommits(md, t.gitLog, t.cfg); len(omitted) > 0 {
+		lines := make([]string, 0, len(omitted))
+		for _, commit := range omitted {
+			lines = append(lines, formatCommitLine(commit))
+		}
+		return false, nil, &pipeline.RefineRequest{
+			UserPromptDelta: "These commits are missing from every section; add each to the fitting section with its short SHA:\n" + strings.Join(lines, "\n"),
+			Reason:          "omitted-commits",
+		}, nil
+	}
+	t.release = Release{
+		Version:  t.version,
+		Date:     t.date,
+		Heading:  parsed.Heading,
+		Sections: parsed.Sections,
+		Footer:   parsed.Footer,
+	}
+	return true, t.release, nil, nil
 }
 
-// 4) Apply: prepend to CHANGELOG.md or print
+// 4) Apply: render the verified release for apply.mode and write it, or print it
 func (t *Task) Apply(ctx context.Context, verified pipeline.VerifiedOutput) (pipeline.ApplyReport, error) {
-	md := verified.(string)
-	switch strings.ToLower(t.cfg.Apply.Mode) {
-	case "print":
-		fmt.Println(md)
-		return pipeline.ApplyReport{DryRun: false, Summary: "printed changelog section", NumActions: 1}, nil
-	case "prepend":
-		path := coalesce(t.cfg.Apply.OutputPath, "./CHANGELOG.md")
-		var existing string
-		if b, err := os.ReadFile(filepath.Clean(path)); err == nil {
-			existing = string(b)
-		}
-		var out strings.Builder
-		out.WriteString(md)
-		out.WriteString("\n")
-		if t.cfg.Apply.EnsureBlankLine {
-			out.WriteString("\n")
-		}
-		out.WriteString(strings.TrimLeft(existing, "\n"))
-		if err := os.WriteFile(filepath.Clean(path), []byte(out.String()), 0o644); err != nil {
-			return pipeline.ApplyReport{}, err
-		}
-		return pipeline.ApplyReport{DryRun: false, Summary: "prepended changelog to " + path, NumActions: 1}, nil
-	default:
+	release := verified.(Release)
+	mode := strings.ToLower(t.cfg.Apply.Mode)
+	if mode == applyModePrint {
+		fmt.Println(release.Markdown())
+		return pipeline.ApplyReport{DryRun: pipeline.DryRunFromContext(ctx), Summary: "printed changelog section", NumActions: 1}, nil
+	}
+	defaultPath, known := defaultOutputPaths[mode]
+	if !known {
 		return pipeline.ApplyReport{}, fmt.Errorf("unknown apply.mode: %s", t.cfg.Apply.Mode)
 	}
+	path := coalesce(t.cfg.Apply.OutputPath, defaultPath)
+	var existing string
+	if b, err := os.ReadFile(filepath.Clean(path)); err == nil {
+		existing = string(b)
+	}
+	updated, action, err := t.render(release, mode, existing)
+	if err != nil {
+		return pipeline.ApplyReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if pipeline.DryRunFromContext(ctx) {
+		fmt.Print(unifiedDiff(path, existing, updated))
+		pipeline.LoggerFromContext(ctx).Info("changelog dry-run", zap.String("path", path), zap.String("mode", t.cfg.Apply.Mode))
+		return pipeline.ApplyReport{DryRun: true, Summary: "dry-run: " + action.planned + " " + path, NumActions: 1}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filepath.Clean(path)), 0o755); err != nil {
+		return pipeline.ApplyReport{}, err
+	}
+	if err := os.WriteFile(filepath.Clean(path), []byte(updated), 0o644); err != nil {
+		return pipeline.ApplyReport{}, err
+	}
+	pipeline.LoggerFromContext(ctx).Info("changelog written", zap.String("path", path), zap.String("mode", t.cfg.Apply.Mode), zap.Int("bytes", len(updated)))
+	return pipeline.ApplyReport{DryRun: false, Summary: action.done + " " + path, NumActions: 1}, nil
 }
 
 // --- helpers ---
 
+// footer is format.footer, or the breaking-changes footer when a pre-grouped commit is breaking.
+func (t *Task) footer() string {
+	if hasBreaking(t.commits) {
+		return strings.TrimSpace(coalesce(t.cfg.Recipe.Format.BreakingFooter, defaultBreakingFooter))
+	}
+	return strings.TrimSpace(t.cfg.Recipe.Format.Footer)
+}
+
 func readAllToBufferCtx(ctx context.Context, r io.Reader, dst *bytes.Buffer) error {
 	sc := bufio.NewScanner(r)
 	done := make(chan error, 1)
@@ -230,30 +304,11 @@ func readAllToBufferCtx(ctx context.Context, r io.Reader, dst *bytes.Buffer) err
 	}
 }
 
-func extractSection(md, title string) string {
-	start := "### " + title
-	i := strings.Index(md, start)
-	if i < 0 {
-		return ""
-	}
-	rest := md[i+len(start):]
-	// Stop at next "### " or end
-	j := strings.Index(rest, "\n### ")
-	if j >= 0 {
-		return rest[:j]
-	}
-	return rest
-}
-
-func countBullets(s string) int {
-	c := 0
-	for _, line := range strings.Split(s, "\n") {
-		lt := strings.TrimSpace(line)
-		if strings.HasPrefix(lt, "- ") || strings.HasPrefix(lt, "* ") {
-			c++
-		}
+func countLines(s string) int {
+	if s == "" {
+		return 0
 	}
-	return c
+	return strings.Count(s, "\n") + 1
 }
 
 func expandTemplate(tmpl string, vars map[string]string) string {
@@ -222,3 +222,58 @@ func TestChangelog_Verify_RefinesOnMissingSection(t *testing.T) {
 		t.Fatalf("expected refine for missing section, got ok=%v refine=%v", ok, refine)
 	}
 }
+
+func TestChangelog_DryRunLeavesFileUntouched(t *testing.T) {
+	tmp := withWorkdir(t)
+	absOut := filepath.Join(tmp, "CHANGELOG.md")
+	existing := "## [1.2.2] - 2024-12-01\n\n- Older entry\n"
+	if err := os.WriteFile(absOut, []byte(existing), 0o644); err != nil {
+		t.Fatalf("seed changelog: %v", err)
+	}
+	cfg := strings.ReplaceAll(cfgYAML, `output_path: "./CHANGELOG.md"`, `output_path: "`+absOut+`"`)
+	cfgPath := withTempFile(t, "task.changelog.yaml", cfg)
+	setEnv(t, "CHANGELOG_VERSION", "1.2.3")
+	setEnv(t, "CHANGELOG_DATE", "2025-01-05")
+	restore := withStdin(t, "feat: add cool thing (#123) abcd123\n")
+	defer restore()
+
+	task, err := changelog.NewFromYAML(cfgPath)
+	if err != nil {
+		t.Fatalf("NewFromYAML: %v", err)
+	}
+	md := strings.TrimSpace(`
+## [1.2.3] - 2025-01-05
+
+### Highlights
+
+- Shiny feature for users (#123, abcd123)
+
+### Features ✨
+
+### Improvements ⚙️
+
+### Docs 📚
+
+### CI & Maintenance
+
+**Upgrade notes:** No breaking changes.
+`)
+	runner := pipeline.Runner{
+		Client:  mockLLM{resp: md},
+		Options: pipeline.RunOptions{MaxAttempts: 1, Timeout: 5 * time.Second, DryRun: true},
+	}
+	report, err := runner.Run(context.Background(), task)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !report.DryRun {
+		t.Fatalf("expected dry-run report, got %+v", report)
+	}
+	b, err := os.ReadFile(absOut)
+	if err != nil {
+		t.Fatalf("read %s: %v", absOut, err)
+	}
+	if string(b) != existing {
+		t.Fatalf("dry-run must not modify CHANGELOG.md, got:\n%s", b)
+	}
+}
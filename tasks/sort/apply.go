@@ -1,20 +1,54 @@
 package sort
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
+	"go.uber.org/zap"
+
 	"github.com/temirov/llm-tasks/internal/pipeline"
 )
 
-func (t *Task) applyMovePlan(plan MovePlan) (pipeline.ApplyReport, error) {
-	count := 0
+func (t *Task) applyMovePlan(ctx context.Context, plan MovePlan) (pipeline.ApplyReport, error) {
+	logger := pipeline.LoggerFromContext(ctx)
+	var runJournal *journal
+	if !plan.DryRun && len(plan.Actions) > 0 {
+		runJournal = newJournal(t.fs, plan.StagingDir, t.now())
+	}
+	count, routed := 0, 0
+	for _, a := range plan.Actions {
+		if a.LowConfidence && !a.NeedsConfirmation {
+			routed++
+		}
+	}
+	skipped := append([]SkippedFile(nil), plan.Skipped...)
+	confirmer := moveConfirmer{confirm: t.confirm, resolved: t.confirm != nil}
 	for _, a := range plan.Actions {
 		if plan.DryRun {
-			fmt.Printf("[DRY] %s -> %s (%.2f) %s\n", a.FromPath, a.ToPath, a.Confidence, a.Reason)
+			// the plan goes to stdout like the changelog diff, whatever the log level
+			fmt.Print(plannedMove(a))
+			logger.Info("dry-run move",
+				zap.String("from", a.FromPath),
+				zap.String("to", a.ToPath),
+				zap.Float64("confidence", a.Confidence),
+				zap.String("reason", a.Reason),
+				zap.Bool("needs_confirmation", a.NeedsConfirmation),
+			)
 			count++
 			continue
 		}
+		if a.NeedsConfirmation {
+			confirmed, reason, err := confirmer.approve(a)
+			if err != nil {
+				return pipeline.ApplyReport{}, fmt.Errorf("confirm move of %s: %w", a.FromPath, err)
+			}
+			if !confirmed {
+				logger.Info("move skipped", zap.String("from", a.FromPath), zap.String("reason", reason))
+				skipped = append(skipped, SkippedFile{Path: a.FromPath, Confidence: a.Confidence, Reason: reason})
+				continue
+			}
+		}
 		if err := t.fs.EnsureDir(a.ToPath); err != nil {
 			return pipeline.ApplyReport{}, err
 		}
@@ -22,16 +56,91 @@ func (t *Task) applyMovePlan(plan MovePlan) (pipeline.ApplyReport, error) {
 		if err := t.fs.MoveFile(a.FromPath, dest); err != nil {
 			return pipeline.ApplyReport{}, err
 		}
-		fmt.Printf("[MOVE] %s -> %s (%.2f)\n", a.FromPath, dest, a.Confidence)
+		if err := runJournal.record(JournalEntry{From: a.FromPath, To: dest, Timestamp: t.now().UTC(), Confidence: a.Confidence}); err != nil {
+			return pipeline.ApplyReport{}, fmt.Errorf("write undo journal: %w", err)
+		}
+		logger.Info("moved",
+			zap.String("from", a.FromPath),
+			zap.String("to", dest),
+			zap.Float64("confidence", a.Confidence),
+		)
 		count++
 	}
+	duplicateExtras := 0
+	for _, group := range plan.Duplicates {
+		logger.Info("duplicates",
+			zap.String("strategy", group.Strategy),
+			zap.String("kept", group.Kept),
+			zap.Strings("extras", group.Extras),
+			zap.String("hash", group.Hash),
+		)
+		duplicateExtras += len(group.Extras)
+	}
+	for _, file := range plan.Skipped {
+		logger.Info("skipped", zap.String("path", file.Path), zap.Float64("confidence", file.Confidence), zap.String("reason", file.Reason))
+	}
+	summary := fmt.Sprintf("sort: %d actions (%s)", count, ternary(plan.DryRun, "dry-run", "applied"))
+	if routed > 0 {
+		summary += fmt.Sprintf(", %d low-confidence routed to %s", routed, inboxSubdir)
+	}
+	if duplicateExtras > 0 {
+		summary += fmt.Sprintf(", %d duplicate(s) in %d group(s)", duplicateExtras, len(plan.Duplicates))
+	}
+	if len(skipped) > 0 {
+		summary += fmt.Sprintf(", %d skipped", len(skipped))
+	}
+	if runJournal != nil {
+		summary += ", undo with: llm-tasks undo " + runJournal.runID
+	}
+	pending, err := t.queueProposals(ctx, plan)
+	if err != nil {
+		return pipeline.ApplyReport{}, fmt.Errorf("queue project proposals: %w", err)
+	}
+	if pending > 0 {
+		summary += fmt.Sprintf(", %d project proposal(s) pending: llm-tasks sort approve", pending)
+	}
 	return pipeline.ApplyReport{
 		DryRun:     plan.DryRun,
-		Summary:    fmt.Sprintf("sort: %d actions (%s)", count, ternary(plan.DryRun, "dry-run", "applied")),
+		Summary:    summary,
 		NumActions: count,
+		Skipped:    len(skipped),
 	}, nil
 }
 
+// queueProposals adds the plan's new-project suggestions to the staging review file and
+// returns how many proposals are pending; dry runs only log them.
+func (t *Task) queueProposals(ctx context.Context, plan MovePlan) (int, error) {
+	logger := pipeline.LoggerFromContext(ctx)
+	for _, proposal := range plan.Proposals {
+		logger.Info("project proposed",
+			zap.String("name", proposal.Name),
+			zap.Strings("keywords", proposal.Keywords),
+			zap.Int("files", proposal.FileCount),
+		)
+	}
+	if plan.DryRun || len(plan.Proposals) == 0 {
+		return 0, nil
+	}
+	pending, err := LoadProposals(t.fs, plan.StagingDir)
+	if err != nil {
+		return 0, err
+	}
+	pending = mergeProposals(pending, plan.Proposals...)
+	if err := SaveProposals(t.fs, plan.StagingDir, pending); err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
+
+// plannedMove renders one dry-run move, e.g. "would move /in/a.csv -> /out/Data/a.csv (confidence 0.90)".
+func plannedMove(a MoveAction) string {
+	line := fmt.Sprintf("would move %s -> %s (confidence %.2f", a.FromPath, a.ToPath, a.Confidence)
+	if a.NeedsConfirmation {
+		line += ", needs confirmation"
+	}
+	return line + ")\n"
+}
+
 func (t *Task) uniquePath(to string) string {
 	base := to
 	ext := filepath.Ext(to)
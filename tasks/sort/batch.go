@@ -0,0 +1,124 @@
+package sort
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+var projectNamePattern = regexp.MustCompile(`^[\w\- ]{2,64}$`)
+
+// Batch is one chunk of the inventory together with what Verify has accepted so far.
+// The runner hands the same *Batch to every attempt, so refines only re-ask about pending files.
+type Batch struct {
+	Files []FileMeta
+
+	actions   []*MoveAction  // indexed like Files; nil until accepted
+	skipped   []*SkippedFile // indexed like Files; set when a policy leaves the file alone
+	pending   []int          // indexes into Files the model still has to classify, in prompt order
+	proposals []ProjectProposal
+}
+
+func newBatch(files []FileMeta) *Batch {
+	batch := &Batch{Files: files, actions: make([]*MoveAction, len(files)), skipped: make([]*SkippedFile, len(files))}
+	for index := range files {
+		batch.pending = append(batch.pending, index)
+	}
+	return batch
+}
+
+// asBatch accepts a *Batch from Split or a bare inventory slice for single-shot use.
+func asBatch(gathered pipeline.GatherOutput) *Batch {
+	if batch, ok := gathered.(*Batch); ok {
+		return batch
+	}
+	return newBatch(gathered.([]FileMeta))
+}
+
+// Len reports the number of files in the batch, used for run logging.
+func (b *Batch) Len() int { return len(b.Files) }
+
+func (b *Batch) plan(cfg config.Sort) MovePlan {
+	plan := MovePlan{DryRun: cfg.Grant.Safety.DryRun, StagingDir: cfg.Grant.BaseDirectories.Staging, Proposals: b.proposals}
+	for index, action := range b.actions {
+		if action != nil {
+			plan.Actions = append(plan.Actions, *action)
+		}
+		if skipped := b.skipped[index]; skipped != nil {
+			plan.Skipped = append(plan.Skipped, *skipped)
+		}
+	}
+	return plan
+}
+
+// partialPlan is the accepted subset, or nil when nothing has been accepted yet.
+func (b *Batch) partialPlan(cfg config.Sort) pipeline.VerifiedOutput {
+	if len(b.pending) == len(b.Files) {
+		return nil
+	}
+	return b.plan(cfg)
+}
+
+// answers keys the results by file index, keeping the first answer for each pending file.
+// Answers for unknown or already accepted ids are ignored, so a model that re-answers the
+// whole batch or reorders its results cannot move a file to another file's folder.
+func (b *Batch) answers(results []LLMResult) map[int]LLMResult {
+	pending := make(map[int]bool, len(b.pending))
+	for _, index := range b.pending {
+		pending[index] = true
+	}
+	answers := make(map[int]LLMResult, len(b.pending))
+	for _, result := range results {
+		if _, seen := answers[result.ID]; pending[result.ID] && !seen {
+			answers[result.ID] = result
+		}
+	}
+	return answers
+}
+
+type fileRejection struct {
+	file   FileMeta
+	id     int
+	code   string
+	detail string
+}
+
+// rejectClassification returns the reason an item must be re-asked. Low confidence is only a
+// rejection under the refine policy; the other policies resolve it in Verify.
+func rejectClassification(item LLMResult, file FileMeta, minConfidence float64, policy string) (fileRejection, bool) {
+	if item.IsNewProject && !projectNamePattern.MatchString(item.ProposedProject) {
+		return fileRejection{file: file, id: item.ID, code: "bad-project-name", detail: fmt.Sprintf("proposed project %q is invalid; use 2–64 characters: letters, numbers, space, dash, underscore", item.ProposedProject)}, true
+	}
+	if item.Confidence < minConfidence && !item.IsNewProject && policy == lowConfidenceRefine {
+		return fileRejection{file: file, id: item.ID, code: "low-confidence", detail: fmt.Sprintf("confidence %.2f is below %.2f; raise it with clearer signals, assign 'Unsorted_Inbox', or propose a new project", item.Confidence, minConfidence)}, true
+	}
+	return fileRejection{}, false
+}
+
+// perFileRefine lists only the rejected files with their ids; the next prompt lists only them too.
+func perFileRefine(failures []fileRejection) *pipeline.RefineRequest {
+	var (
+		delta strings.Builder
+		codes []string
+	)
+	fmt.Fprintf(&delta, "Your classifications for the other files were accepted. Re-classify only these %d file(s) and return exactly one element in \"results\" per file, with its id:\n", len(failures))
+	for _, failure := range failures {
+		fmt.Fprintf(&delta, "- id %d, %s: %s\n", failure.id, failure.file.AbsolutePath, failure.detail)
+		if !containsString(codes, failure.code) {
+			codes = append(codes, failure.code)
+		}
+	}
+	return &pipeline.RefineRequest{UserPromptDelta: delta.String(), Reason: strings.Join(codes, ",")}
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
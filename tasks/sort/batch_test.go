@@ -0,0 +1,94 @@
+package sort
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+func classificationResponse(t *testing.T, results ...LLMResult) pipeline.LLMResponse {
+	t.Helper()
+	encoded, err := json.Marshal(classifications{Results: results})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pipeline.LLMResponse{RawText: string(encoded)}
+}
+
+func TestVerifyRefinesOnlyFailingFiles(t *testing.T) {
+	task, _ := newMemTask(t, nil)
+	batch := newBatch([]FileMeta{
+		{AbsolutePath: "/downloads/a.csv", BaseName: "a", Extension: ".csv"},
+		{AbsolutePath: "/downloads/b.stl", BaseName: "b", Extension: ".stl"},
+		{AbsolutePath: "/downloads/c.bin", BaseName: "c", Extension: ".bin"},
+	})
+
+	ok, partial, refine, err := task.Verify(context.Background(), batch, classificationResponse(t,
+		LLMResult{ID: 0, TargetSubdir: "Data", Confidence: 0.9},
+		LLMResult{ID: 1, TargetSubdir: "Print", Confidence: 0.2},
+		LLMResult{ID: 2, IsNewProject: true, ProposedProject: "?", Confidence: 0.8},
+	))
+	if err != nil || ok {
+		t.Fatalf("expected a refine, got ok=%v err=%v", ok, err)
+	}
+	if refine == nil || refine.Reason != "low-confidence,bad-project-name" {
+		t.Fatalf("unexpected refine %+v", refine)
+	}
+	for _, want := range []string{"only these 2 file(s)", "- id 1, /downloads/b.stl: confidence 0.20 is below 0.60", "- id 2, /downloads/c.bin: proposed project \"?\" is invalid"} {
+		if !strings.Contains(refine.UserPromptDelta, want) {
+			t.Fatalf("expected refine to contain %q, got:\n%s", want, refine.UserPromptDelta)
+		}
+	}
+	if strings.Contains(refine.UserPromptDelta, "a.csv") {
+		t.Fatalf("accepted file must not be re-asked:\n%s", refine.UserPromptDelta)
+	}
+	if actions := partial.(MovePlan).Actions; len(actions) != 1 || actions[0].FromPath != "/downloads/a.csv" {
+		t.Fatalf("expected the accepted move as partial output, got %+v", actions)
+	}
+
+	request, err := task.Prompt(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	if strings.Contains(request.UserPrompt, "a.csv") || !strings.Contains(request.UserPrompt, `"id":1`) || !strings.Contains(request.UserPrompt, `"id":2`) {
+		t.Fatalf("refine prompt must list only the pending files with their ids:\n%s", request.UserPrompt)
+	}
+
+	core, observed := observer.New(zap.InfoLevel)
+	ok, _, refine, err = task.Verify(pipeline.WithLogger(context.Background(), zap.New(core)), batch, classificationResponse(t,
+		LLMResult{ID: 2, TargetSubdir: "Print", Confidence: 0.9},
+	))
+	if err != nil || ok || refine == nil || refine.Reason != "missing-classification" || !strings.Contains(refine.UserPromptDelta, "- id 1, /downloads/b.stl") {
+		t.Fatalf("expected the unanswered file to be re-asked, got ok=%v refine=%+v err=%v", ok, refine, err)
+	}
+	rejected := observed.FilterMessage("classifications rejected").All()
+	if len(rejected) != 1 || rejected[0].ContextMap()["accepted"] != int64(1) || rejected[0].ContextMap()["rejected"] != int64(1) {
+		t.Fatalf("expected 1 accepted and 1 rejected classification to be logged, got %+v", rejected)
+	}
+
+	// the model re-answers the whole batch in another order: the accepted a.csv and c.bin keep
+	// their folders and only b.stl takes its answer
+	ok, verified, refine, err := task.Verify(context.Background(), batch, classificationResponse(t,
+		LLMResult{ID: 1, TargetSubdir: "Misc", Confidence: 0.7},
+		LLMResult{ID: 2, TargetSubdir: "Other", Confidence: 0.9},
+		LLMResult{ID: 0, TargetSubdir: "Other", Confidence: 0.9},
+	))
+	if err != nil || !ok || refine != nil {
+		t.Fatalf("expected acceptance, got ok=%v refine=%+v err=%v", ok, refine, err)
+	}
+	actions := verified.(MovePlan).Actions
+	if len(actions) != 3 {
+		t.Fatalf("expected all three moves, got %+v", actions)
+	}
+	for index, want := range []string{"/downloads/_sorted/Data/a.csv", "/downloads/_sorted/Misc/b.stl", "/downloads/_sorted/Print/c.bin"} {
+		if actions[index].ToPath != want {
+			t.Fatalf("action %d: expected %s, got %s", index, want, actions[index].ToPath)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package sort
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+// Values of the recipe's duplicates.strategy.
+const (
+	duplicatesKeepNewest = "keep_newest"
+	duplicatesMoveExtras = "move_extras"
+	duplicatesSkip       = "skip"
+)
+
+// duplicatesSubdir receives the extra copies under the move_extras strategy.
+const duplicatesSubdir = "_duplicates"
+
+// DuplicateGroup is a set of byte-identical files and what the plan does with them.
+type DuplicateGroup struct {
+	Hash     string   `json:"hash"`
+	Strategy string   `json:"strategy"`
+	Kept     string   `json:"kept,omitempty"`
+	Extras   []string `json:"extras"`
+}
+
+// duplicateDecisions is what Gather derives from the duplicate groups: files withheld from
+// classification, and the moves or skips that replace their classification.
+type duplicateDecisions struct {
+	groups   []DuplicateGroup
+	withheld map[string]bool
+	actions  []MoveAction
+	skipped  []SkippedFile
+}
+
+type duplicateSet struct {
+	hash  string
+	files []fsops.FileInfo
+}
+
+func duplicatesStrategy(cfg config.Sort) (string, error) {
+	switch strategy := strings.TrimSpace(cfg.Duplicates.Strategy); strategy {
+	case "", duplicatesKeepNewest, duplicatesMoveExtras, duplicatesSkip:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("duplicates.strategy %q must be one of %s, %s, %s", strategy, duplicatesKeepNewest, duplicatesMoveExtras, duplicatesSkip)
+	}
+}
+
+// findDuplicates groups byte-identical files. Only files sharing a size are hashed.
+// Within a group the newest file comes first; ties go to the shorter, then lexically first path,
+// which prefers "report.pdf" over "report (1).pdf".
+func findDuplicates(fs fsops.Ops, infos []fsops.FileInfo) ([]duplicateSet, error) {
+	bySize := map[int64][]fsops.FileInfo{}
+	for _, info := range infos {
+		if info.SizeBytes > 0 {
+			bySize[info.SizeBytes] = append(bySize[info.SizeBytes], info)
+		}
+	}
+	byHash := map[string][]fsops.FileInfo{}
+	var hashes []string
+	for _, info := range infos {
+		if len(bySize[info.SizeBytes]) < 2 {
+			continue
+		}
+		hash, err := fs.HashFile(info.AbsolutePath)
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", info.AbsolutePath, err)
+		}
+		if _, seen := byHash[hash]; !seen {
+			hashes = append(hashes, hash)
+		}
+		byHash[hash] = append(byHash[hash], info)
+	}
+
+	var groups []duplicateSet
+	for _, hash := range hashes {
+		group := byHash[hash]
+		if len(group) < 2 {
+			continue
+		}
+		slices.SortStableFunc(group, func(a, b fsops.FileInfo) int {
+			if !a.ModTime.Equal(b.ModTime) {
+				return b.ModTime.Compare(a.ModTime)
+			}
+			if len(a.AbsolutePath) != len(b.AbsolutePath) {
+				return len(a.AbsolutePath) - len(b.AbsolutePath)
+			}
+			return strings.Compare(a.AbsolutePath, b.AbsolutePath)
+		})
+		groups = append(groups, duplicateSet{hash: hash, files: group})
+	}
+	return groups, nil
+}
+
+// decideDuplicates applies the strategy to every group:
+//   - keep_newest: the newest copy is classified; the others stay in place, listed as skipped.
+//   - move_extras: the newest copy is classified; the others move to <staging>/_duplicates.
+//   - skip: no copy is classified or moved; all of them are listed as skipped.
+func (t *Task) decideDuplicates(cfg config.Sort, strategy string, sets []duplicateSet) duplicateDecisions {
+	decisions := duplicateDecisions{withheld: map[string]bool{}}
+	for _, set := range sets {
+		group := set.files
+		report := DuplicateGroup{Hash: set.hash, Strategy: strategy}
+		extras := group[1:]
+		if strategy == duplicatesSkip {
+			extras = group
+		} else {
+			report.Kept = group[0].AbsolutePath
+		}
+		for _, extra := range extras {
+			report.Extras = append(report.Extras, extra.AbsolutePath)
+			decisions.withheld[extra.AbsolutePath] = true
+			switch strategy {
+			case duplicatesMoveExtras:
+				decisions.actions = append(decisions.actions, MoveAction{
+					FromPath:   extra.AbsolutePath,
+					ToPath:     t.fs.FS.Join(cfg.Grant.BaseDirectories.Staging, duplicatesSubdir, extra.BaseName+extra.Extension),
+					Confidence: 1,
+					Reason:     "duplicate of " + group[0].AbsolutePath,
+				})
+			case duplicatesKeepNewest:
+				decisions.skipped = append(decisions.skipped, SkippedFile{Path: extra.AbsolutePath, Confidence: 1, Reason: "duplicate of " + group[0].AbsolutePath})
+			case duplicatesSkip:
+				decisions.skipped = append(decisions.skipped, SkippedFile{Path: extra.AbsolutePath, Confidence: 1, Reason: "duplicate group left in place"})
+			}
+		}
+		decisions.groups = append(decisions.groups, report)
+	}
+	return decisions
+}
@@ -0,0 +1,119 @@
+package sort
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGatherAppliesDuplicateStrategy(t *testing.T) {
+	const (
+		original = "/downloads/report.pdf"
+		copy1    = "/downloads/report (1).pdf"
+		copy2    = "/downloads/report (2).pdf"
+		sameSize = "/downloads/other.pdf"
+		unique   = "/downloads/notes.txt"
+	)
+	testCases := []struct {
+		strategy        string
+		expectedGather  []string
+		expectedActions []MoveAction
+		expectedSkipped []SkippedFile
+		expectedGroup   *DuplicateGroup
+	}{
+		{
+			strategy:       "",
+			expectedGather: []string{unique, sameSize, copy1, copy2, original},
+		},
+		{
+			strategy:       duplicatesKeepNewest,
+			expectedGather: []string{unique, sameSize, copy2},
+			expectedSkipped: []SkippedFile{
+				{Path: original, Confidence: 1, Reason: "duplicate of " + copy2},
+				{Path: copy1, Confidence: 1, Reason: "duplicate of " + copy2},
+			},
+			expectedGroup: &DuplicateGroup{Strategy: duplicatesKeepNewest, Kept: copy2, Extras: []string{original, copy1}},
+		},
+		{
+			strategy:       duplicatesMoveExtras,
+			expectedGather: []string{unique, sameSize, copy2},
+			expectedActions: []MoveAction{
+				{FromPath: original, ToPath: "/downloads/_sorted/_duplicates/report.pdf", Confidence: 1, Reason: "duplicate of " + copy2},
+				{FromPath: copy1, ToPath: "/downloads/_sorted/_duplicates/report (1).pdf", Confidence: 1, Reason: "duplicate of " + copy2},
+			},
+			expectedGroup: &DuplicateGroup{Strategy: duplicatesMoveExtras, Kept: copy2, Extras: []string{original, copy1}},
+		},
+		{
+			strategy:       duplicatesSkip,
+			expectedGather: []string{unique, sameSize},
+			expectedSkipped: []SkippedFile{
+				{Path: copy2, Confidence: 1, Reason: "duplicate group left in place"},
+				{Path: original, Confidence: 1, Reason: "duplicate group left in place"},
+				{Path: copy1, Confidence: 1, Reason: "duplicate group left in place"},
+			},
+			expectedGroup: &DuplicateGroup{Strategy: duplicatesSkip, Extras: []string{copy2, original, copy1}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("strategy="+testCase.strategy, func(t *testing.T) {
+			task, mem := newMemTask(t, map[string]string{
+				original: "%PDF same",
+				copy1:    "%PDF same",
+				copy2:    "%PDF same",
+				sameSize: "%PDF diff",
+				unique:   "hello",
+			})
+			// copy2 is newest; original and copy1 tie, so the shorter name sorts first among them
+			base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			stamps := map[string]time.Time{original: base, copy1: base, copy2: base.Add(time.Hour)}
+			for path, stamp := range stamps {
+				if err := mem.Fs.Chtimes(path, stamp, stamp); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg, _ := task.cfgProv.Load()
+			cfg.Duplicates.Strategy = testCase.strategy
+			task.cfgProv = staticConfigProvider{cfg: cfg}
+
+			gathered, err := task.Gather(context.Background())
+			if err != nil {
+				t.Fatalf("gather: %v", err)
+			}
+			var gatheredPaths []string
+			for _, file := range gathered.([]FileMeta) {
+				gatheredPaths = append(gatheredPaths, file.AbsolutePath)
+			}
+			if !reflect.DeepEqual(gatheredPaths, testCase.expectedGather) {
+				t.Fatalf("expected gathered %v, got %v", testCase.expectedGather, gatheredPaths)
+			}
+
+			merged, err := task.Merge(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+			plan := merged.(MovePlan)
+			if !reflect.DeepEqual(plan.Actions, testCase.expectedActions) {
+				t.Fatalf("expected actions %+v, got %+v", testCase.expectedActions, plan.Actions)
+			}
+			if !reflect.DeepEqual(plan.Skipped, testCase.expectedSkipped) {
+				t.Fatalf("expected skipped %+v, got %+v", testCase.expectedSkipped, plan.Skipped)
+			}
+			if testCase.expectedGroup == nil {
+				if len(plan.Duplicates) != 0 {
+					t.Fatalf("expected no duplicate groups, got %+v", plan.Duplicates)
+				}
+				return
+			}
+			if len(plan.Duplicates) != 1 || plan.Duplicates[0].Hash == "" {
+				t.Fatalf("expected one hashed duplicate group, got %+v", plan.Duplicates)
+			}
+			group := plan.Duplicates[0]
+			group.Hash = ""
+			if !reflect.DeepEqual(group, *testCase.expectedGroup) {
+				t.Fatalf("expected group %+v, got %+v", *testCase.expectedGroup, group)
+			}
+		})
+	}
+}
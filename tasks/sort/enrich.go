@@ -0,0 +1,156 @@
+package sort
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"  // register decoders for DecodeConfig
+	_ "image/jpeg" // register decoders for DecodeConfig
+	_ "image/png"  // register decoders for DecodeConfig
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+const (
+	defaultEnrichmentBudget = 8192
+	sniffBytes              = 512
+	snippetMaxRunes         = 300
+	maxArchiveEntries       = 25
+
+	zipEndOfCentralDirSignature = 0x06054b50
+	zipCentralDirEntrySignature = 0x02014b50
+	zipEndOfCentralDirMinLength = 22
+	zipCentralDirEntryMinLength = 46
+)
+
+var (
+	pdfTitlePattern  = regexp.MustCompile(`/Title\s*\(((?:\\.|[^\\)])*)\)`)
+	whitespaceRunsRe = regexp.MustCompile(`\s+`)
+)
+
+// enrichFile adds content-derived signals to meta, reading at most budget bytes:
+// half from the head (sniffing, text, image headers, PDF info) and, for archives and PDFs,
+// half from the tail where the zip central directory and PDF trailer live.
+// Enrichment is best effort; unreadable content leaves the filename-only metadata intact.
+func enrichFile(fs fsops.Ops, meta *FileMeta, modTime time.Time, budget int64) error {
+	if !modTime.IsZero() {
+		meta.ModifiedAt = modTime.UTC().Format(time.RFC3339)
+	}
+	if budget <= 0 {
+		budget = defaultEnrichmentBudget
+	}
+	if meta.SizeBytes == 0 {
+		return nil
+	}
+
+	headSize := min(meta.SizeBytes, budget/2)
+	head, err := fs.ReadRange(meta.AbsolutePath, 0, headSize)
+	if err != nil {
+		return err
+	}
+	meta.SniffedMIME = http.DetectContentType(head[:min(len(head), sniffBytes)])
+
+	switch {
+	case isText(meta.SniffedMIME, head):
+		meta.TextSnippet = textSnippet(head)
+	case strings.HasPrefix(meta.SniffedMIME, "image/"):
+		if cfg, _, decodeErr := image.DecodeConfig(bytes.NewReader(head)); decodeErr == nil {
+			meta.ImageWidth, meta.ImageHeight = cfg.Width, cfg.Height
+		}
+	}
+
+	isZip := meta.SniffedMIME == "application/zip" || meta.Extension == ".zip" || meta.Extension == ".3mf"
+	isPDF := meta.SniffedMIME == "application/pdf"
+	if !isZip && !isPDF {
+		return nil
+	}
+
+	tail := head
+	if meta.SizeBytes > headSize {
+		tailSize := min(meta.SizeBytes-headSize, budget-headSize)
+		tail, err = fs.ReadRange(meta.AbsolutePath, meta.SizeBytes-tailSize, tailSize)
+		if err != nil {
+			return err
+		}
+	}
+	if isZip {
+		meta.ArchiveEntries = zipEntryNames(tail, meta.SizeBytes)
+	}
+	if isPDF {
+		meta.PDFTitle = pdfTitle(head)
+		if meta.PDFTitle == "" {
+			meta.PDFTitle = pdfTitle(tail)
+		}
+	}
+	return nil
+}
+
+func isText(sniffed string, head []byte) bool {
+	if strings.HasPrefix(sniffed, "text/") {
+		return true
+	}
+	return sniffed == "application/octet-stream" && utf8.Valid(head) && !bytes.ContainsRune(head, 0)
+}
+
+func textSnippet(head []byte) string {
+	// drop a possibly truncated trailing rune
+	for len(head) > 0 && !utf8.Valid(head) {
+		head = head[:len(head)-1]
+	}
+	collapsed := strings.TrimSpace(whitespaceRunsRe.ReplaceAllString(string(head), " "))
+	runes := []rune(collapsed)
+	if len(runes) > snippetMaxRunes {
+		return string(runes[:snippetMaxRunes]) + "…"
+	}
+	return collapsed
+}
+
+// zipEntryNames lists entries from the zip central directory when it lies inside tail.
+func zipEntryNames(tail []byte, fileSize int64) []string {
+	eocd := -1
+	for i := len(tail) - zipEndOfCentralDirMinLength; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == zipEndOfCentralDirSignature {
+			eocd = i
+			break
+		}
+	}
+	if eocd < 0 {
+		return nil
+	}
+	directoryOffset := int64(binary.LittleEndian.Uint32(tail[eocd+16:]))
+	tailStart := fileSize - int64(len(tail))
+	if directoryOffset < tailStart {
+		return nil
+	}
+
+	var names []string
+	for cursor := int(directoryOffset - tailStart); cursor+zipCentralDirEntryMinLength <= eocd && len(names) < maxArchiveEntries; {
+		if binary.LittleEndian.Uint32(tail[cursor:]) != zipCentralDirEntrySignature {
+			break
+		}
+		nameLength := int(binary.LittleEndian.Uint16(tail[cursor+28:]))
+		extraLength := int(binary.LittleEndian.Uint16(tail[cursor+30:]))
+		commentLength := int(binary.LittleEndian.Uint16(tail[cursor+32:]))
+		nameStart := cursor + zipCentralDirEntryMinLength
+		if nameStart+nameLength > len(tail) {
+			break
+		}
+		names = append(names, string(tail[nameStart:nameStart+nameLength]))
+		cursor = nameStart + nameLength + extraLength + commentLength
+	}
+	return names
+}
+
+func pdfTitle(data []byte) string {
+	match := pdfTitlePattern.FindSubmatch(data)
+	if match == nil {
+		return ""
+	}
+	replacer := strings.NewReplacer(`\(`, "(", `\)`, ")", `\\`, `\`, `\n`, " ", `\r`, " ", `\t`, " ")
+	return strings.TrimSpace(replacer.Replace(string(match[1])))
+}
@@ -0,0 +1,125 @@
+package sort
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+func zipBytes(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range names {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = entry.Write([]byte(strings.Repeat("x", 64)))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func pngBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEnrichFile(t *testing.T) {
+	modTime := time.Date(2025, 4, 2, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		extension string
+		content   []byte
+		budget    int64
+		check     func(t *testing.T, meta FileMeta)
+	}{
+		{
+			name:      "text snippet collapses whitespace",
+			extension: ".txt",
+			content:   []byte("Invoice   #42\n\nTotal due: $10"),
+			check: func(t *testing.T, meta FileMeta) {
+				if meta.TextSnippet != "Invoice #42 Total due: $10" {
+					t.Fatalf("unexpected snippet %q", meta.TextSnippet)
+				}
+				if !strings.HasPrefix(meta.SniffedMIME, "text/plain") {
+					t.Fatalf("unexpected sniffed mime %q", meta.SniffedMIME)
+				}
+			},
+		},
+		{
+			name:      "zip entries from central directory",
+			extension: ".zip",
+			content:   zipBytes(t, "Metadata/plate_1.gcode", "3D/3dmodel.model"),
+			check: func(t *testing.T, meta FileMeta) {
+				want := []string{"Metadata/plate_1.gcode", "3D/3dmodel.model"}
+				if !reflect.DeepEqual(meta.ArchiveEntries, want) {
+					t.Fatalf("expected entries %v, got %v", want, meta.ArchiveEntries)
+				}
+			},
+		},
+		{
+			name:      "zip central directory outside budget is skipped",
+			extension: ".zip",
+			content:   zipBytes(t, "a.txt", "b.txt", "c.txt", "d.txt"),
+			budget:    64,
+			check: func(t *testing.T, meta FileMeta) {
+				if len(meta.ArchiveEntries) != 0 {
+					t.Fatalf("expected no entries within a 64 byte budget, got %v", meta.ArchiveEntries)
+				}
+			},
+		},
+		{
+			name:      "image dimensions",
+			extension: ".png",
+			content:   pngBytes(t, 640, 480),
+			check: func(t *testing.T, meta FileMeta) {
+				if meta.ImageWidth != 640 || meta.ImageHeight != 480 {
+					t.Fatalf("expected 640x480, got %dx%d", meta.ImageWidth, meta.ImageHeight)
+				}
+			},
+		},
+		{
+			name:      "pdf title from trailer",
+			extension: ".pdf",
+			content:   []byte("%PDF-1.4\n" + strings.Repeat("0", 9000) + "\n<< /Title (Quarterly \\(Q3\\) Report) >>\n%%EOF"),
+			check: func(t *testing.T, meta FileMeta) {
+				if meta.PDFTitle != "Quarterly (Q3) Report" {
+					t.Fatalf("unexpected pdf title %q", meta.PDFTitle)
+				}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mem := fsops.NewMem()
+			path := "/downloads/file" + testCase.extension
+			if err := mem.WriteFile(path, testCase.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			meta := FileMeta{AbsolutePath: path, BaseName: "file", Extension: testCase.extension, SizeBytes: int64(len(testCase.content))}
+			if err := enrichFile(fsops.NewOps(mem), &meta, modTime, testCase.budget); err != nil {
+				t.Fatalf("enrich: %v", err)
+			}
+			if meta.ModifiedAt != "2025-04-02T10:30:00Z" {
+				t.Fatalf("unexpected modified_at %q", meta.ModifiedAt)
+			}
+			testCase.check(t, meta)
+		})
+	}
+}
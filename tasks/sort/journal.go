@@ -0,0 +1,177 @@
+package sort
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/temirov/llm-tasks/internal/fsops"
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+const (
+	// journalDirName lives under the staging dir; dot-directories are skipped by Inventory.
+	journalDirName       = ".llm-tasks-journal"
+	journalFileExtension = ".jsonl"
+	journalUndoneSuffix  = ".undone"
+	runIDTimeLayout      = "20060102T150405Z"
+)
+
+// runIDPattern matches the IDs newJournal allocates; undo rejects anything else so a run ID
+// cannot point outside the journal directory.
+var runIDPattern = regexp.MustCompile(`^\d{8}T\d{6}Z(-\d+)?$`)
+
+// JournalEntry records one applied move so it can be reverted by `llm-tasks undo`.
+type JournalEntry struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Timestamp  time.Time `json:"timestamp"`
+	Confidence float64   `json:"confidence"`
+}
+
+// UndoConflict is a journal entry that could not be reverted.
+type UndoConflict struct {
+	Entry  JournalEntry
+	Reason string
+}
+
+type UndoReport struct {
+	RunID     string
+	Restored  int
+	Conflicts []UndoConflict
+}
+
+type journal struct {
+	fs    fsops.Ops
+	path  string
+	runID string
+}
+
+// newJournal allocates a run ID from the current time, suffixing it if a journal for that second exists.
+func newJournal(fs fsops.Ops, staging string, now time.Time) *journal {
+	base := now.UTC().Format(runIDTimeLayout)
+	runID := base
+	for i := 1; fs.FileExists(journalPath(fs, staging, runID)) || fs.FileExists(journalPath(fs, staging, runID)+journalUndoneSuffix); i++ {
+		runID = fmt.Sprintf("%s-%d", base, i)
+	}
+	return &journal{fs: fs, path: journalPath(fs, staging, runID), runID: runID}
+}
+
+// record appends one JSON line per move, so earlier entries are never rewritten and survive
+// a crash mid-run.
+func (j *journal) record(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := j.fs.EnsureDir(j.path); err != nil {
+		return err
+	}
+	return j.fs.FS.AppendFile(j.path, append(line, '\n'), 0o644)
+}
+
+func journalPath(fs fsops.Ops, staging, runID string) string {
+	return fs.FS.Join(staging, journalDirName, runID+journalFileExtension)
+}
+
+func writeJournal(fs fsops.Ops, path string, entries []JournalEntry) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	if err := fs.EnsureDir(path); err != nil {
+		return err
+	}
+	return fs.FS.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func readJournal(fs fsops.Ops, path string) ([]JournalEntry, error) {
+	data, err := fs.FS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []JournalEntry
+	lines := strings.Split(string(data), "\n")
+	for lineNumber, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			if lineNumber == len(lines)-1 {
+				// an unterminated last line is an append cut short by a crash; its move never finished
+				break
+			}
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Undo moves the files recorded for runID back to their original location, newest first.
+// Entries that cannot be restored are reported as conflicts and kept in the journal so the
+// command can be re-run after they are resolved; a fully reverted journal is marked undone.
+func Undo(ctx context.Context, fs fsops.Ops, staging, runID string) (UndoReport, error) {
+	logger := pipeline.LoggerFromContext(ctx)
+	if !runIDPattern.MatchString(runID) {
+		return UndoReport{}, fmt.Errorf("invalid run ID %q: expected one like %s", runID, runIDTimeLayout)
+	}
+	path := journalPath(fs, staging, runID)
+	entries, err := readJournal(fs, path)
+	if err != nil {
+		if fs.FileExists(path + journalUndoneSuffix) {
+			return UndoReport{}, fmt.Errorf("run %s was already undone", runID)
+		}
+		return UndoReport{}, fmt.Errorf("read journal for run %s: %w", runID, err)
+	}
+
+	report := UndoReport{RunID: runID}
+	var remaining []JournalEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		reason, undoErr := undoEntry(fs, entry)
+		if undoErr != nil {
+			return report, undoErr
+		}
+		if reason != "" {
+			logger.Warn("undo conflict", zap.String("from", entry.To), zap.String("to", entry.From), zap.String("reason", reason))
+			report.Conflicts = append(report.Conflicts, UndoConflict{Entry: entry, Reason: reason})
+			remaining = append([]JournalEntry{entry}, remaining...)
+			continue
+		}
+		logger.Info("restored", zap.String("from", entry.To), zap.String("to", entry.From))
+		report.Restored++
+	}
+
+	if len(remaining) > 0 {
+		return report, writeJournal(fs, path, remaining)
+	}
+	return report, fs.FS.Rename(path, path+journalUndoneSuffix)
+}
+
+func undoEntry(fs fsops.Ops, entry JournalEntry) (conflict string, err error) {
+	if !fs.FileExists(entry.To) {
+		return "sorted file no longer exists", nil
+	}
+	if fs.FileExists(entry.From) {
+		return "original path is occupied", nil
+	}
+	if err := fs.EnsureDir(entry.From); err != nil {
+		return "", err
+	}
+	if err := fs.MoveFile(entry.To, entry.From); err != nil {
+		return "", errors.Join(fmt.Errorf("restore %s", entry.From), err)
+	}
+	return "", nil
+}
@@ -0,0 +1,155 @@
+package sort
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+type staticConfigProvider struct{ cfg config.Sort }
+
+func (p staticConfigProvider) Load() (config.Sort, error) { return p.cfg, nil }
+
+func newMemTask(t *testing.T, files map[string]string) (*Task, fsops.Mem) {
+	t.Helper()
+	mem := fsops.NewMem()
+	for path, body := range files {
+		if err := mem.MkdirAll(mem.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := mem.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var cfg config.Sort
+	cfg.Grant.BaseDirectories.Downloads = "/downloads"
+	cfg.Grant.BaseDirectories.Staging = "/downloads/_sorted"
+	task := NewWithDeps(fsops.NewOps(mem), staticConfigProvider{cfg: cfg}).(*Task)
+	task.now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return task, mem
+}
+
+func TestApplyWritesJournalAndUndoRestores(t *testing.T) {
+	task, _ := newMemTask(t, map[string]string{
+		"/downloads/a.csv":               "a",
+		"/downloads/b.stl":               "b",
+		"/downloads/_sorted/Data/a.csv":  "older copy",
+		"/downloads/_sorted/Print/x.txt": "unrelated",
+	})
+	plan := MovePlan{
+		StagingDir: "/downloads/_sorted",
+		Actions: []MoveAction{
+			{FromPath: "/downloads/a.csv", ToPath: "/downloads/_sorted/Data/a.csv", Confidence: 0.9},
+			{FromPath: "/downloads/b.stl", ToPath: "/downloads/_sorted/Print/b.stl", Confidence: 0.8},
+		},
+	}
+
+	report, err := task.applyMovePlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	const runID = "20250301T120000Z"
+	if !strings.Contains(report.Summary, runID) {
+		t.Fatalf("expected summary to name run %s, got %q", runID, report.Summary)
+	}
+	entries, err := readJournal(task.fs, journalPath(task.fs, "/downloads/_sorted", runID))
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if len(entries) != 2 || entries[0].To != "/downloads/_sorted/Data/a-1.csv" || entries[0].Confidence != 0.9 {
+		t.Fatalf("journal must record the final unique destination, got %+v", entries)
+	}
+
+	undo, err := Undo(context.Background(), task.fs, "/downloads/_sorted", runID)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if undo.Restored != 2 || len(undo.Conflicts) != 0 {
+		t.Fatalf("unexpected undo report %+v", undo)
+	}
+	for _, path := range []string{"/downloads/a.csv", "/downloads/b.stl", "/downloads/_sorted/Data/a.csv"} {
+		if !task.fs.FileExists(path) {
+			t.Fatalf("expected %s to exist after undo", path)
+		}
+	}
+	if _, err := Undo(context.Background(), task.fs, "/downloads/_sorted", runID); err == nil || !strings.Contains(err.Error(), "already undone") {
+		t.Fatalf("expected second undo to report the run as undone, got %v", err)
+	}
+}
+
+func TestUndoReportsConflictsAndKeepsThemInJournal(t *testing.T) {
+	task, mem := newMemTask(t, map[string]string{
+		"/downloads/a.csv": "a",
+		"/downloads/b.csv": "b",
+	})
+	plan := MovePlan{
+		StagingDir: "/downloads/_sorted",
+		Actions: []MoveAction{
+			{FromPath: "/downloads/a.csv", ToPath: "/downloads/_sorted/Data/a.csv"},
+			{FromPath: "/downloads/b.csv", ToPath: "/downloads/_sorted/Data/b.csv"},
+		},
+	}
+	if _, err := task.applyMovePlan(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	// a new download reuses the original name of a.csv
+	if err := mem.WriteFile("/downloads/a.csv", []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const runID = "20250301T120000Z"
+	undo, err := Undo(context.Background(), task.fs, "/downloads/_sorted", runID)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if undo.Restored != 1 || len(undo.Conflicts) != 1 || undo.Conflicts[0].Entry.From != "/downloads/a.csv" {
+		t.Fatalf("unexpected undo report %+v", undo)
+	}
+	remaining, err := readJournal(task.fs, journalPath(task.fs, "/downloads/_sorted", runID))
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].From != "/downloads/a.csv" {
+		t.Fatalf("expected only the conflicting entry to remain, got %+v", remaining)
+	}
+}
+
+func TestUndoRejectsRunIDsOutsideTheJournal(t *testing.T) {
+	task, mem := newMemTask(t, map[string]string{
+		"/outside.jsonl": `{"from":"/etc/passwd","to":"/downloads/a.csv"}` + "\n",
+	})
+	for _, runID := range []string{"../../outside", "20250301T120000Z/../../../outside", "latest"} {
+		if _, err := Undo(context.Background(), task.fs, "/downloads/_sorted", runID); err == nil || !strings.Contains(err.Error(), "invalid run ID") {
+			t.Fatalf("expected %q to be rejected, got %v", runID, err)
+		}
+	}
+	if data, err := mem.ReadFile("/outside.jsonl"); err != nil || !strings.Contains(string(data), "/etc/passwd") {
+		t.Fatalf("expected the file outside the journal to be untouched, got %q, %v", data, err)
+	}
+}
+
+func TestJournalAppendsAndIgnoresTruncatedLastLine(t *testing.T) {
+	task, mem := newMemTask(t, nil)
+	runJournal := newJournal(task.fs, "/downloads/_sorted", task.now())
+	for _, name := range []string{"a.csv", "b.csv"} {
+		entry := JournalEntry{From: "/downloads/" + name, To: "/downloads/_sorted/Data/" + name, Confidence: 0.9}
+		if err := runJournal.record(entry); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	if err := mem.AppendFile(runJournal.path, []byte(`{"from":"/downloads/c.c`), 0o644); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	entries, err := readJournal(task.fs, runJournal.path)
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if len(entries) != 2 || entries[0].From != "/downloads/a.csv" || entries[1].From != "/downloads/b.csv" {
+		t.Fatalf("expected both complete entries in order, got %+v", entries)
+	}
+}
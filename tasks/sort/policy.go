@@ -0,0 +1,85 @@
+package sort
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/temirov/llm-tasks/internal/config"
+)
+
+// Values of the recipe's low_confidence_policy.
+const (
+	lowConfidenceRefine       = "refine"
+	lowConfidenceRouteToInbox = "route_to_inbox"
+	lowConfidenceSkip         = "skip"
+	lowConfidenceAsk          = "ask"
+)
+
+const inboxSubdir = "Unsorted_Inbox"
+
+// SkippedFile is a file the plan deliberately leaves where it is.
+type SkippedFile struct {
+	Path       string  `json:"path"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+func lowConfidencePolicy(cfg config.Sort) (string, error) {
+	switch policy := strings.TrimSpace(cfg.LowConfidencePolicy); policy {
+	case "":
+		return lowConfidenceRefine, nil
+	case lowConfidenceRefine, lowConfidenceRouteToInbox, lowConfidenceSkip, lowConfidenceAsk:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("low_confidence_policy %q must be one of %s, %s, %s, %s", policy, lowConfidenceRefine, lowConfidenceRouteToInbox, lowConfidenceSkip, lowConfidenceAsk)
+	}
+}
+
+// confirmFunc asks whether an uncertain move should go ahead (low_confidence_policy: ask).
+type confirmFunc func(action MoveAction) (bool, error)
+
+// terminalConfirm prompts on out and reads y/N answers from in. It returns nil when in is not a
+// terminal, in which case uncertain moves are skipped rather than guessed.
+func terminalConfirm(in *os.File, out io.Writer) confirmFunc {
+	info, err := in.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	reader := bufio.NewReader(in)
+	return func(action MoveAction) (bool, error) {
+		if _, err := fmt.Fprintf(out, "move %s -> %s (confidence %.2f)? [y/N] ", action.FromPath, action.ToPath, action.Confidence); err != nil {
+			return false, err
+		}
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes", nil
+	}
+}
+
+// moveConfirmer resolves the terminal prompt lazily, on the first move that needs it.
+type moveConfirmer struct {
+	confirm  confirmFunc
+	resolved bool
+}
+
+// approve reports whether action may go ahead and, if not, why.
+func (c *moveConfirmer) approve(action MoveAction) (bool, string, error) {
+	if !c.resolved {
+		c.confirm, c.resolved = terminalConfirm(os.Stdin, os.Stderr), true
+	}
+	if c.confirm == nil {
+		return false, "not confirmed: stdin is not a terminal", nil
+	}
+	confirmed, err := c.confirm(action)
+	if err != nil || confirmed {
+		return confirmed, "", err
+	}
+	return false, "declined at prompt", nil
+}
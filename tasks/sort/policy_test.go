@@ -0,0 +1,128 @@
+package sort
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerifyLowConfidencePolicies(t *testing.T) {
+	files := []FileMeta{
+		{AbsolutePath: "/downloads/a.csv", BaseName: "a", Extension: ".csv"},
+		{AbsolutePath: "/downloads/b.bin", BaseName: "b", Extension: ".bin"},
+	}
+	testCases := []struct {
+		policy          string
+		expectAccepted  bool
+		expectedActions []MoveAction
+		expectedSkipped []SkippedFile
+	}{
+		{policy: "", expectAccepted: false},
+		{policy: lowConfidenceRefine, expectAccepted: false},
+		{
+			policy:         lowConfidenceRouteToInbox,
+			expectAccepted: true,
+			expectedActions: []MoveAction{
+				{FromPath: "/downloads/a.csv", ToPath: "/downloads/_sorted/Data/a.csv", Confidence: 0.9, Reason: "csv"},
+				{FromPath: "/downloads/b.bin", ToPath: "/downloads/_sorted/Unsorted_Inbox/b.bin", Confidence: 0.3, Reason: "low confidence 0.30, routed to inbox,binary", LowConfidence: true},
+			},
+		},
+		{
+			policy:          lowConfidenceSkip,
+			expectAccepted:  true,
+			expectedActions: []MoveAction{{FromPath: "/downloads/a.csv", ToPath: "/downloads/_sorted/Data/a.csv", Confidence: 0.9, Reason: "csv"}},
+			expectedSkipped: []SkippedFile{{Path: "/downloads/b.bin", Confidence: 0.3, Reason: "low confidence 0.30 < 0.60"}},
+		},
+		{
+			policy:         lowConfidenceAsk,
+			expectAccepted: true,
+			expectedActions: []MoveAction{
+				{FromPath: "/downloads/a.csv", ToPath: "/downloads/_sorted/Data/a.csv", Confidence: 0.9, Reason: "csv"},
+				{FromPath: "/downloads/b.bin", ToPath: "/downloads/_sorted/Firmware/b.bin", Confidence: 0.3, Reason: "binary", LowConfidence: true, NeedsConfirmation: true},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("policy="+testCase.policy, func(t *testing.T) {
+			task, _ := newMemTask(t, nil)
+			cfg, _ := task.cfgProv.Load()
+			cfg.Thresholds.MinConfidence = 0.6
+			cfg.LowConfidencePolicy = testCase.policy
+			task.cfgProv = staticConfigProvider{cfg: cfg}
+
+			ok, verified, refine, err := task.Verify(context.Background(), files, classificationResponse(t,
+				LLMResult{ID: 0, TargetSubdir: "Data", Confidence: 0.9, Signals: []string{"csv"}},
+				LLMResult{ID: 1, TargetSubdir: "Firmware", Confidence: 0.3, Signals: []string{"binary"}},
+			))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if ok != testCase.expectAccepted {
+				t.Fatalf("expected accepted=%v, got %v (refine %+v)", testCase.expectAccepted, ok, refine)
+			}
+			if !ok {
+				if refine == nil || refine.Reason != "low-confidence" {
+					t.Fatalf("expected a low-confidence refine, got %+v", refine)
+				}
+				return
+			}
+			plan := verified.(MovePlan)
+			if len(plan.Actions) != len(testCase.expectedActions) {
+				t.Fatalf("expected actions %+v, got %+v", testCase.expectedActions, plan.Actions)
+			}
+			for index, action := range plan.Actions {
+				if action != testCase.expectedActions[index] {
+					t.Fatalf("action %d: expected %+v, got %+v", index, testCase.expectedActions[index], action)
+				}
+			}
+			if len(plan.Skipped) != len(testCase.expectedSkipped) || (len(plan.Skipped) > 0 && plan.Skipped[0] != testCase.expectedSkipped[0]) {
+				t.Fatalf("expected skipped %+v, got %+v", testCase.expectedSkipped, plan.Skipped)
+			}
+		})
+	}
+}
+
+func TestApplyAsksBeforeUncertainMoves(t *testing.T) {
+	task, _ := newMemTask(t, map[string]string{
+		"/downloads/a.bin": "a",
+		"/downloads/b.bin": "b",
+	})
+	var asked []string
+	task.confirm = func(action MoveAction) (bool, error) {
+		asked = append(asked, action.FromPath)
+		return action.FromPath == "/downloads/a.bin", nil
+	}
+	plan := MovePlan{
+		StagingDir: "/downloads/_sorted",
+		Actions: []MoveAction{
+			{FromPath: "/downloads/a.bin", ToPath: "/downloads/_sorted/Firmware/a.bin", Confidence: 0.4, LowConfidence: true, NeedsConfirmation: true},
+			{FromPath: "/downloads/b.bin", ToPath: "/downloads/_sorted/Firmware/b.bin", Confidence: 0.3, LowConfidence: true, NeedsConfirmation: true},
+		},
+		Skipped: []SkippedFile{{Path: "/downloads/c.bin", Confidence: 0.1, Reason: "low confidence 0.10 < 0.60"}},
+	}
+
+	report, err := task.applyMovePlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(asked) != 2 {
+		t.Fatalf("expected both uncertain moves to be confirmed, asked %v", asked)
+	}
+	if report.NumActions != 1 || report.Skipped != 2 || !strings.Contains(report.Summary, "2 skipped") {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !task.fs.FileExists("/downloads/_sorted/Firmware/a.bin") || !task.fs.FileExists("/downloads/b.bin") {
+		t.Fatal("expected only the confirmed file to move")
+	}
+}
+
+func TestGatherRejectsUnknownLowConfidencePolicy(t *testing.T) {
+	task, _ := newMemTask(t, nil)
+	cfg, _ := task.cfgProv.Load()
+	cfg.LowConfidencePolicy = "guess"
+	task.cfgProv = staticConfigProvider{cfg: cfg}
+	if _, err := task.Gather(context.Background()); err == nil || !strings.Contains(err.Error(), "low_confidence_policy") {
+		t.Fatalf("expected a low_confidence_policy error, got %v", err)
+	}
+}
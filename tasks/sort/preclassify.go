@@ -0,0 +1,178 @@
+package sort
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/temirov/llm-tasks/internal/config"
+)
+
+const (
+	// Keyword prefixes for rules that match the whole file name ("model.stl").
+	globKeywordPrefix  = "glob:"
+	regexKeywordPrefix = "re:"
+
+	extensionMatchScore = 0.7
+	tokenMatchScore     = 0.4
+	patternMatchScore   = 0.9
+	maxKeywordScore     = 0.99
+	// ambiguityMargin: a runner-up project scoring within this margin of the best sends the file to the model.
+	ambiguityMargin = 0.2
+)
+
+type keywordRuleKind int
+
+const (
+	// plain keywords without separators match the extension or a whole basename token
+	tokenKeywordRule keywordRuleKind = iota
+	// plain keywords with separators ("gemini_generated", "dall·e") match a basename substring
+	substringKeywordRule
+	globKeywordRule
+	regexKeywordRule
+)
+
+type keywordRule struct {
+	kind    keywordRuleKind
+	keyword string
+	pattern *regexp.Regexp
+}
+
+type projectRules struct {
+	name   string
+	target string
+	rules  []keywordRule
+}
+
+// preclassifier assigns files to projects from their configured keywords without asking the model.
+type preclassifier struct {
+	projects      []projectRules
+	minConfidence float64
+}
+
+type keywordMatch struct {
+	project    string
+	target     string
+	confidence float64
+	signals    []string
+}
+
+func newPreclassifier(cfg config.Sort) (preclassifier, error) {
+	minConfidence := cfg.Preclassify.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = cfg.Thresholds.MinConfidence
+	}
+	if minConfidence <= 0 {
+		minConfidence = defaultMinConfidence
+	}
+	classifier := preclassifier{minConfidence: minConfidence}
+	for _, project := range cfg.Projects {
+		compiled := projectRules{name: project.Name, target: project.Target}
+		if compiled.target == "" {
+			compiled.target = project.Name
+		}
+		for _, keyword := range project.Keywords {
+			rule, err := parseKeywordRule(keyword)
+			if err != nil {
+				return preclassifier{}, fmt.Errorf("project %q: %w", project.Name, err)
+			}
+			if rule.keyword != "" {
+				compiled.rules = append(compiled.rules, rule)
+			}
+		}
+		classifier.projects = append(classifier.projects, compiled)
+	}
+	return classifier, nil
+}
+
+func parseKeywordRule(keyword string) (keywordRule, error) {
+	keyword = strings.TrimSpace(keyword)
+	switch {
+	case strings.HasPrefix(keyword, globKeywordPrefix):
+		glob := strings.ToLower(strings.TrimPrefix(keyword, globKeywordPrefix))
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return keywordRule{}, fmt.Errorf("keyword %q: %w", keyword, err)
+		}
+		return keywordRule{kind: globKeywordRule, keyword: glob}, nil
+	case strings.HasPrefix(keyword, regexKeywordPrefix):
+		expression := strings.TrimPrefix(keyword, regexKeywordPrefix)
+		pattern, err := regexp.Compile("(?i)" + expression)
+		if err != nil {
+			return keywordRule{}, fmt.Errorf("keyword %q: %w", keyword, err)
+		}
+		return keywordRule{kind: regexKeywordRule, keyword: expression, pattern: pattern}, nil
+	}
+	keyword = strings.ToLower(keyword)
+	if len(basenameTokens(keyword)) > 1 {
+		return keywordRule{kind: substringKeywordRule, keyword: keyword}, nil
+	}
+	return keywordRule{kind: tokenKeywordRule, keyword: keyword}, nil
+}
+
+// classify returns the best-scoring project for file. It reports false when nothing reaches
+// the confidence threshold or when another project scores too close to call.
+func (p preclassifier) classify(file FileMeta) (keywordMatch, bool) {
+	baseName := strings.ToLower(file.BaseName)
+	fileName := baseName + strings.ToLower(file.Extension)
+	extension := strings.TrimPrefix(strings.ToLower(file.Extension), ".")
+	tokens := basenameTokens(baseName)
+
+	var best, runnerUp keywordMatch
+	for _, project := range p.projects {
+		candidate := keywordMatch{project: project.name, target: project.target}
+		for _, rule := range project.rules {
+			score, signal := rule.score(fileName, baseName, extension, tokens)
+			if score == 0 {
+				continue
+			}
+			candidate.confidence += score
+			candidate.signals = append(candidate.signals, signal)
+		}
+		candidate.confidence = min(candidate.confidence, maxKeywordScore)
+		if candidate.confidence > best.confidence {
+			best, runnerUp = candidate, best
+		} else if candidate.confidence > runnerUp.confidence {
+			runnerUp = candidate
+		}
+	}
+	if best.confidence < p.minConfidence || runnerUp.confidence > best.confidence-ambiguityMargin {
+		return keywordMatch{}, false
+	}
+	return best, true
+}
+
+func (r keywordRule) score(fileName, baseName, extension string, tokens []string) (float64, string) {
+	switch r.kind {
+	case globKeywordRule:
+		if matched, _ := filepath.Match(r.keyword, fileName); matched {
+			return patternMatchScore, "glob:" + r.keyword
+		}
+	case regexKeywordRule:
+		if r.pattern.MatchString(fileName) {
+			return patternMatchScore, "re:" + r.keyword
+		}
+	case substringKeywordRule:
+		if strings.Contains(baseName, r.keyword) {
+			return tokenMatchScore, "name:" + r.keyword
+		}
+	case tokenKeywordRule:
+		if r.keyword == extension {
+			return extensionMatchScore, "ext:" + r.keyword
+		}
+		for _, token := range tokens {
+			if token == r.keyword {
+				return tokenMatchScore, "token:" + r.keyword
+			}
+		}
+	}
+	return 0, ""
+}
+
+// basenameTokens splits a name on every non-letter, non-digit rune.
+func basenameTokens(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,106 @@
+package sort
+
+import (
+	"context"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/config"
+)
+
+func keywordConfig(projects map[string][]string) config.Sort {
+	var cfg config.Sort
+	cfg.Thresholds.MinConfidence = 0.6
+	for _, name := range []string{"3D_Printing", "Data_CSV", "Photos", "Scans"} {
+		keywords, ok := projects[name]
+		if !ok {
+			continue
+		}
+		cfg.Projects = append(cfg.Projects, struct {
+			Name     string   `yaml:"name"`
+			Target   string   `yaml:"target"`
+			Keywords []string `yaml:"keywords"`
+		}{Name: name, Target: name, Keywords: keywords})
+	}
+	return cfg
+}
+
+func TestPreclassifierClassify(t *testing.T) {
+	cfg := keywordConfig(map[string][]string{
+		"3D_Printing": {"stl", "3mf", "bambu", "glob:*_plate_*.gcode"},
+		"Data_CSV":    {"csv", "sales_tax"},
+		"Photos":      {"jpg", "re:^img_\\d{4}"},
+		"Scans":       {"jpg", "scan"},
+	})
+	classifier, err := newPreclassifier(cfg)
+	if err != nil {
+		t.Fatalf("newPreclassifier: %v", err)
+	}
+
+	testCases := []struct {
+		name            string
+		file            FileMeta
+		expectedProject string
+		expectedMatch   bool
+	}{
+		{name: "extension", file: FileMeta{BaseName: "model", Extension: ".stl"}, expectedProject: "3D_Printing", expectedMatch: true},
+		{name: "extension and token", file: FileMeta{BaseName: "Bambu-benchy", Extension: ".3MF"}, expectedProject: "3D_Printing", expectedMatch: true},
+		{name: "glob", file: FileMeta{BaseName: "cube_plate_1", Extension: ".gcode"}, expectedProject: "3D_Printing", expectedMatch: true},
+		{name: "separator keyword matches substring", file: FileMeta{BaseName: "CA_sales_tax_2024", Extension: ".xlsx"}, expectedMatch: false},
+		{name: "substring adds to extension", file: FileMeta{BaseName: "CA_sales_tax_2024", Extension: ".csv"}, expectedProject: "Data_CSV", expectedMatch: true},
+		{name: "regex breaks a tie", file: FileMeta{BaseName: "IMG_2041", Extension: ".jpg"}, expectedProject: "Photos", expectedMatch: true},
+		{name: "ambiguous extension goes to the model", file: FileMeta{BaseName: "holiday", Extension: ".jpg"}, expectedMatch: false},
+		{name: "token alone is below threshold", file: FileMeta{BaseName: "bambu notes", Extension: ".txt"}, expectedMatch: false},
+		{name: "no keywords", file: FileMeta{BaseName: "resume", Extension: ".docx"}, expectedMatch: false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			match, ok := classifier.classify(testCase.file)
+			if ok != testCase.expectedMatch {
+				t.Fatalf("expected match=%v, got %v (%+v)", testCase.expectedMatch, ok, match)
+			}
+			if ok && match.project != testCase.expectedProject {
+				t.Fatalf("expected project %s, got %s (signals %v)", testCase.expectedProject, match.project, match.signals)
+			}
+		})
+	}
+}
+
+func TestNewPreclassifierRejectsBadPattern(t *testing.T) {
+	cfg := keywordConfig(map[string][]string{"Photos": {"re:("}})
+	if _, err := newPreclassifier(cfg); err == nil {
+		t.Fatal("expected an error for an invalid regular expression")
+	}
+}
+
+func TestGatherSendsOnlyUnmatchedFilesToModel(t *testing.T) {
+	task, _ := newMemTask(t, map[string]string{
+		"/downloads/model.stl":  "solid",
+		"/downloads/resume.pdf": "%PDF",
+	})
+	cfg := keywordConfig(map[string][]string{"3D_Printing": {"stl"}})
+	cfg.Grant.BaseDirectories.Downloads = "/downloads"
+	cfg.Grant.BaseDirectories.Staging = "/downloads/_sorted"
+	cfg.Preclassify.Enabled = true
+	task.cfgProv = staticConfigProvider{cfg: cfg}
+
+	gathered, err := task.Gather(context.Background())
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	files := gathered.([]FileMeta)
+	if len(files) != 1 || files[0].BaseName != "resume" {
+		t.Fatalf("expected only resume.pdf to need the model, got %+v", files)
+	}
+	if len(task.Inventory) != 2 {
+		t.Fatalf("expected the full inventory to be kept, got %d files", len(task.Inventory))
+	}
+
+	merged, err := task.Merge(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	actions := merged.(MovePlan).Actions
+	if len(actions) != 1 || actions[0].ToPath != "/downloads/_sorted/3D_Printing/model.stl" || actions[0].Reason != "keywords:ext:stl" {
+		t.Fatalf("unexpected preclassified actions: %+v", actions)
+	}
+}
@@ -0,0 +1,158 @@
+package sort
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/fsops"
+)
+
+const (
+	// proposalsFileName lives in the staging dir next to the journal and is meant to be read by people.
+	proposalsFileName       = ".llm-tasks-proposals.yaml"
+	maxProposalSampleFiles  = 10
+	proposalsFileHeaderText = "# New projects proposed by the sort model.\n# Approve with: llm-tasks sort approve <name>... (or --all); delete entries you do not want.\n"
+)
+
+// ProjectProposal is a new project suggested by the model, waiting for review.
+type ProjectProposal struct {
+	Name       string   `yaml:"name" json:"name"`
+	Target     string   `yaml:"target" json:"target"`
+	Keywords   []string `yaml:"keywords" json:"keywords"`
+	Confidence float64  `yaml:"confidence" json:"confidence"`
+	FileCount  int      `yaml:"file_count" json:"file_count"`
+	Files      []string `yaml:"files" json:"files"`
+}
+
+type proposalsFile struct {
+	Proposals []ProjectProposal `yaml:"proposals"`
+}
+
+// ProposalsPath returns the review file for a staging directory.
+func ProposalsPath(fs fsops.Ops, staging string) string {
+	return fs.FS.Join(staging, proposalsFileName)
+}
+
+// LoadProposals reads pending proposals; a missing review file means none are pending.
+func LoadProposals(fs fsops.Ops, staging string) ([]ProjectProposal, error) {
+	path := ProposalsPath(fs, staging)
+	if !fs.FileExists(path) {
+		return nil, nil
+	}
+	data, err := fs.FS.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file proposalsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return file.Proposals, nil
+}
+
+// SaveProposals rewrites the review file with the given pending proposals.
+func SaveProposals(fs fsops.Ops, staging string, proposals []ProjectProposal) error {
+	path := ProposalsPath(fs, staging)
+	if proposals == nil {
+		proposals = []ProjectProposal{}
+	}
+	data, err := yaml.Marshal(proposalsFile{Proposals: proposals})
+	if err != nil {
+		return err
+	}
+	if err := fs.EnsureDir(path); err != nil {
+		return err
+	}
+	return fs.FS.WriteFile(path, append([]byte(proposalsFileHeaderText), data...), 0o644)
+}
+
+// mergeProposals folds incoming proposals into pending ones by case-insensitive name,
+// unioning keywords and sample files and keeping the highest confidence.
+func mergeProposals(pending []ProjectProposal, incoming ...ProjectProposal) []ProjectProposal {
+	merged := append([]ProjectProposal(nil), pending...)
+	for _, proposal := range incoming {
+		index := findProposal(merged, proposal.Name)
+		if index < 0 {
+			proposal.Files = proposal.Files[:min(len(proposal.Files), maxProposalSampleFiles)]
+			merged = append(merged, proposal)
+			continue
+		}
+		existing := &merged[index]
+		existing.Keywords = appendUnique(existing.Keywords, proposal.Keywords...)
+		existing.Confidence = max(existing.Confidence, proposal.Confidence)
+		existing.FileCount += proposal.FileCount
+		for _, file := range proposal.Files {
+			if len(existing.Files) >= maxProposalSampleFiles {
+				break
+			}
+			existing.Files = appendUnique(existing.Files, file)
+		}
+	}
+	return merged
+}
+
+func findProposal(proposals []ProjectProposal, name string) int {
+	for i, proposal := range proposals {
+		if strings.EqualFold(proposal.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// knownProject reports whether name is already a configured project.
+func knownProject(cfg config.Sort, name string) bool {
+	for _, project := range cfg.Projects {
+		if strings.EqualFold(project.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, extra ...string) []string {
+	for _, value := range extra {
+		duplicate := false
+		for _, existing := range values {
+			if strings.EqualFold(existing, value) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// SelectProposals splits pending proposals into the approved ones, matched by case-insensitive
+// name (or all of them), and the ones that stay pending.
+func SelectProposals(pending []ProjectProposal, names []string, all bool) (approved, remaining []ProjectProposal, err error) {
+	if all {
+		return pending, nil, nil
+	}
+	for _, name := range names {
+		if findProposal(pending, name) < 0 {
+			return nil, nil, fmt.Errorf("no pending proposal named %q", name)
+		}
+	}
+	for _, proposal := range pending {
+		selected := false
+		for _, name := range names {
+			if strings.EqualFold(proposal.Name, name) {
+				selected = true
+				break
+			}
+		}
+		if selected {
+			approved = append(approved, proposal)
+		} else {
+			remaining = append(remaining, proposal)
+		}
+	}
+	return approved, remaining, nil
+}
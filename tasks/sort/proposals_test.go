@@ -0,0 +1,101 @@
+package sort
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/temirov/llm-tasks/internal/config"
+	"github.com/temirov/llm-tasks/internal/fsops"
+	"github.com/temirov/llm-tasks/internal/pipeline"
+)
+
+func TestVerifyCollectsProjectProposals(t *testing.T) {
+	var cfg config.Sort
+	cfg.Grant.BaseDirectories.Staging = "/staging"
+	cfg.Projects = append(cfg.Projects, struct {
+		Name     string   `yaml:"name"`
+		Target   string   `yaml:"target"`
+		Keywords []string `yaml:"keywords"`
+	}{Name: "Taxes", Target: "Taxes"})
+	task := NewWithDeps(fsops.NewOps(fsops.NewMem()), staticConfigProvider{cfg: cfg}).(*Task)
+
+	files := []FileMeta{
+		{AbsolutePath: "/d/rules.pdf", BaseName: "rules", Extension: ".pdf"},
+		{AbsolutePath: "/d/meeple.png", BaseName: "meeple", Extension: ".png"},
+		{AbsolutePath: "/d/w2.pdf", BaseName: "w2", Extension: ".pdf"},
+	}
+	response := `{"results":[
+ {"id":0,"project_name":"","target_subdir":"Unsorted_Inbox","confidence":0.4,"is_new_project":true,"proposed_project":"Board Games","proposed_keywords":["rulebook"],"signals":[]},
+ {"id":1,"project_name":"","target_subdir":"Unsorted_Inbox","confidence":0.7,"is_new_project":true,"proposed_project":"board games","proposed_keywords":["meeple","Rulebook"],"signals":[]},
+ {"id":2,"project_name":"","target_subdir":"Taxes","confidence":0.5,"is_new_project":true,"proposed_project":"taxes","proposed_keywords":["w2"],"signals":[]}
+]}`
+
+	ok, verified, refine, err := task.Verify(context.Background(), files, pipeline.LLMResponse{RawText: response})
+	if err != nil || !ok || refine != nil {
+		t.Fatalf("expected acceptance, got ok=%v refine=%v err=%v", ok, refine, err)
+	}
+	expected := []ProjectProposal{{
+		Name:       "Board Games",
+		Target:     "Board Games",
+		Keywords:   []string{"rulebook", "meeple"},
+		Confidence: 0.7,
+		FileCount:  2,
+		Files:      []string{"rules.pdf", "meeple.png"},
+	}}
+	if got := verified.(MovePlan).Proposals; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected proposals:\n got %+v\nwant %+v", got, expected)
+	}
+}
+
+func TestApplyQueuesProposalsForReview(t *testing.T) {
+	task, _ := newMemTask(t, nil)
+	plan := MovePlan{
+		StagingDir: "/downloads/_sorted",
+		Proposals:  []ProjectProposal{{Name: "Board Games", Target: "Board Games", Keywords: []string{"rulebook"}, FileCount: 1, Files: []string{"rules.pdf"}}},
+	}
+
+	dryRun := plan
+	dryRun.DryRun = true
+	if _, err := task.applyMovePlan(context.Background(), dryRun); err != nil {
+		t.Fatalf("dry-run apply: %v", err)
+	}
+	if pending, _ := LoadProposals(task.fs, plan.StagingDir); len(pending) != 0 {
+		t.Fatalf("dry run must not queue proposals, got %+v", pending)
+	}
+
+	if _, err := task.applyMovePlan(context.Background(), plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	plan.Proposals = []ProjectProposal{
+		{Name: "board games", Target: "board games", Keywords: []string{"meeple"}, FileCount: 2, Files: []string{"meeple.png"}},
+		{Name: "Recipes", Target: "Recipes", FileCount: 1, Files: []string{"soup.txt"}},
+	}
+	report, err := task.applyMovePlan(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !strings.Contains(report.Summary, "2 project proposal(s) pending") {
+		t.Fatalf("expected pending proposals in summary, got %q", report.Summary)
+	}
+
+	pending, err := LoadProposals(task.fs, plan.StagingDir)
+	if err != nil {
+		t.Fatalf("load proposals: %v", err)
+	}
+	if len(pending) != 2 || pending[0].FileCount != 3 || !reflect.DeepEqual(pending[0].Keywords, []string{"rulebook", "meeple"}) {
+		t.Fatalf("unexpected pending proposals: %+v", pending)
+	}
+
+	approved, remaining, err := SelectProposals(pending, []string{"RECIPES"}, false)
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if len(approved) != 1 || approved[0].Name != "Recipes" || len(remaining) != 1 || remaining[0].Name != "Board Games" {
+		t.Fatalf("unexpected selection: approved=%+v remaining=%+v", approved, remaining)
+	}
+	if _, _, err := SelectProposals(pending, []string{"Unknown"}, false); err == nil {
+		t.Fatal("expected an error for an unknown proposal name")
+	}
+}
@@ -7,18 +7,39 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/temirov/llm-tasks/internal/config"
 	"github.com/temirov/llm-tasks/internal/fsops"
 	"github.com/temirov/llm-tasks/internal/pipeline"
 )
 
+const (
+	defaultBatchSize        = 50
+	defaultBatchConcurrency = 4
+	// responseTokensPerFile budgets one LLMResult object; small batches keep the historical minimum.
+	responseTokensPerFile = 120
+	minResponseTokens     = 1200
+	defaultMinConfidence  = 0.6
+)
+
 type Task struct {
 	fs      fsops.Ops
 	cfgProv SortConfigProvider
+	now     func() time.Time
 
 	Inventory []FileMeta
 	Plan      MovePlan
+	// preclassified holds keyword-matched moves decided in Gather; Merge adds them to the plan.
+	preclassified []MoveAction
+	// duplicates holds the duplicate-group decisions made in Gather; Merge adds them to the plan.
+	duplicates duplicateDecisions
+	// confirm answers NeedsConfirmation moves; nil means prompt on the terminal.
+	confirm confirmFunc
+	// only restricts Gather to these absolute paths; nil sorts the whole downloads directory.
+	only map[string]bool
 }
 
 func New() pipeline.Pipeline {
@@ -29,7 +50,17 @@ func New() pipeline.Pipeline {
 }
 
 func NewWithDeps(fs fsops.Ops, cfg SortConfigProvider) pipeline.Pipeline {
-	return &Task{fs: fs, cfgProv: cfg}
+	return &Task{fs: fs, cfgProv: cfg, now: time.Now}
+}
+
+// NewForFiles sorts only the listed files from the downloads directory; `llm-tasks watch sort`
+// uses it to classify each batch of new arrivals.
+func NewForFiles(fs fsops.Ops, cfg SortConfigProvider, paths []string) *Task {
+	only := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		only[fs.FS.Clean(path)] = true
+	}
+	return &Task{fs: fs, cfgProv: cfg, now: time.Now, only: only}
 }
 
 // DefaultFS exported for wiring from the runner
@@ -41,9 +72,20 @@ type FileMeta struct {
 	Extension    string `json:"extension"`
 	MIMEType     string `json:"mime"`
 	SizeBytes    int64  `json:"size_bytes"`
+
+	// Content signals, filled only when enrichment is enabled.
+	ModifiedAt     string   `json:"modified_at,omitempty"`
+	SniffedMIME    string   `json:"sniffed_mime,omitempty"`
+	TextSnippet    string   `json:"text_snippet,omitempty"`
+	ArchiveEntries []string `json:"archive_entries,omitempty"`
+	ImageWidth     int      `json:"image_width,omitempty"`
+	ImageHeight    int      `json:"image_height,omitempty"`
+	PDFTitle       string   `json:"pdf_title,omitempty"`
 }
 
 type LLMResult struct {
+	// ID echoes the "id" of the file in the prompt; results are matched to files by it.
+	ID               int      `json:"id"`
 	ProjectName      string   `json:"project_name"`
 	TargetSubdir     string   `json:"target_subdir"`
 	Confidence       float64  `json:"confidence"`
@@ -58,11 +100,61 @@ type MoveAction struct {
 	ToPath     string  `json:"to"`
 	Confidence float64 `json:"confidence"`
 	Reason     string  `json:"reason"`
+	// LowConfidence marks moves kept below thresholds.min_confidence by low_confidence_policy.
+	LowConfidence bool `json:"low_confidence,omitempty"`
+	// NeedsConfirmation moves are only applied after an interactive yes (policy "ask").
+	NeedsConfirmation bool `json:"needs_confirmation,omitempty"`
 }
 
 type MovePlan struct {
-	Actions []MoveAction `json:"actions"`
-	DryRun  bool         `json:"dry_run"`
+	Actions    []MoveAction `json:"actions"`
+	DryRun     bool         `json:"dry_run"`
+	StagingDir string       `json:"staging_dir"`
+	// Proposals are new projects suggested by the model; Apply queues them for `llm-tasks sort approve`.
+	Proposals []ProjectProposal `json:"proposals,omitempty"`
+	// Skipped lists files left in place on purpose, e.g. by low_confidence_policy: skip.
+	Skipped []SkippedFile `json:"skipped,omitempty"`
+	// Duplicates reports byte-identical file groups and the strategy applied to them.
+	Duplicates []DuplicateGroup `json:"duplicates,omitempty"`
+}
+
+// classificationsSchema is the strict structured-output schema for the classifier reply.
+// OpenAI requires an object at the root, so the LLMResult array is wrapped in "results".
+const classificationsSchema = `{
+  "type": "object",
+  "properties": {
+    "results": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer"},
+          "project_name": {"type": "string"},
+          "target_subdir": {"type": "string"},
+          "confidence": {"type": "number", "minimum": 0, "maximum": 1},
+          "is_new_project": {"type": "boolean"},
+          "proposed_project": {"type": "string"},
+          "proposed_keywords": {"type": "array", "items": {"type": "string"}},
+          "signals": {"type": "array", "items": {"type": "string"}}
+        },
+        "required": ["id", "project_name", "target_subdir", "confidence", "is_new_project", "proposed_project", "proposed_keywords", "signals"],
+        "additionalProperties": false
+      }
+    }
+  },
+  "required": ["results"],
+  "additionalProperties": false
+}`
+
+// promptFile is a file as listed in the prompt; ID is its index in the batch and stays the
+// same across refine attempts, which only list the files still pending.
+type promptFile struct {
+	ID int `json:"id"`
+	FileMeta
+}
+
+type classifications struct {
+	Results []LLMResult `json:"results"`
 }
 
 func (t *Task) Name() string { return "sort" }
@@ -73,32 +165,91 @@ func (t *Task) Gather(ctx context.Context) (pipeline.GatherOutput, error) {
 	if err != nil {
 		return nil, err
 	}
+	if _, err := lowConfidencePolicy(cfg); err != nil {
+		return nil, err
+	}
+	strategy, err := duplicatesStrategy(cfg)
+	if err != nil {
+		return nil, err
+	}
 	infos, err := t.fs.Inventory(cfg.Grant.BaseDirectories.Downloads)
 	if err != nil {
 		return nil, err
 	}
+	if t.only != nil {
+		infos = t.selected(infos)
+	}
+	t.duplicates = duplicateDecisions{}
+	if strategy != "" {
+		sets, findErr := findDuplicates(t.fs, infos)
+		if findErr != nil {
+			return nil, findErr
+		}
+		t.duplicates = t.decideDuplicates(cfg, strategy, sets)
+	}
+	var classifier preclassifier
+	if cfg.Preclassify.Enabled {
+		if classifier, err = newPreclassifier(cfg); err != nil {
+			return nil, fmt.Errorf("preclassify rules: %w", err)
+		}
+	}
+	logger := pipeline.LoggerFromContext(ctx)
+	inventory := make([]FileMeta, 0, len(infos))
 	result := make([]FileMeta, 0, len(infos))
+	t.preclassified = nil
 	for _, info := range infos {
-		result = append(result, FileMeta{
+		meta := FileMeta{
 			AbsolutePath: info.AbsolutePath,
 			BaseName:     info.BaseName,
 			Extension:    info.Extension,
 			MIMEType:     info.MIMEType,
 			SizeBytes:    info.SizeBytes,
-		})
+		}
+		if t.duplicates.withheld[meta.AbsolutePath] {
+			inventory = append(inventory, meta)
+			continue
+		}
+		if cfg.Enrichment.Enabled {
+			if enrichErr := enrichFile(t.fs, &meta, info.ModTime, cfg.Enrichment.MaxBytesPerFile); enrichErr != nil {
+				logger.Warn("enrichment skipped", zap.String("path", info.AbsolutePath), zap.Error(enrichErr))
+			}
+		}
+		inventory = append(inventory, meta)
+		if match, ok := classifier.classify(meta); ok {
+			t.preclassified = append(t.preclassified, MoveAction{
+				FromPath:   meta.AbsolutePath,
+				ToPath:     t.stagedPath(cfg, match.target, meta),
+				Confidence: match.confidence,
+				Reason:     "keywords:" + strings.Join(match.signals, ","),
+			})
+			continue
+		}
+		result = append(result, meta)
 	}
-	t.Inventory = result
+	t.Inventory = inventory
+	logger.Info("sort inventory",
+		zap.String("downloads", cfg.Grant.BaseDirectories.Downloads),
+		zap.Int("files", len(inventory)),
+		zap.Int("preclassified", len(t.preclassified)),
+		zap.Int("duplicates", len(t.duplicates.withheld)),
+		zap.Bool("enriched", cfg.Enrichment.Enabled),
+	)
 	return result, nil
 }
 
 // 2) Prompt
 func (t *Task) Prompt(ctx context.Context, gathered pipeline.GatherOutput) (pipeline.LLMRequest, error) {
-	files := gathered.([]FileMeta)
+	batch := asBatch(gathered)
+	files := make([]promptFile, 0, len(batch.pending))
+	for _, index := range batch.pending {
+		files = append(files, promptFile{ID: index, FileMeta: batch.Files[index]})
+	}
 	filesJSON, _ := json.Marshal(files)
 
 	system := strings.TrimSpace(`
 You classify files into project folders using only the provided metadata.
-- Return JSON array, one element per input in the same order.
+- Content signals (sniffed_mime, text_snippet, archive_entries, image size, pdf_title) outweigh generic file names.
+- Return one element in "results" per input file, with the file's "id".
 - If no project fits, propose a concise new project and keywords.
 - Confidence 0..1. No prose. No code fences.
 `)
@@ -108,87 +259,190 @@ You classify files into project folders using only the provided metadata.
 
 File metadata (array):
 %s
-
-Respond as JSON array with objects:
-{"project_name":"","target_subdir":"","confidence":0.0,"is_new_project":false,"proposed_project":"","proposed_keywords":[],"signals":[]}
 `, t.loadProjectListJSON(), string(filesJSON))
 
 	return pipeline.LLMRequest{
 		SystemPrompt: system,
 		UserPrompt:   user,
-		MaxTokens:    1200,
+		JSONSchema:   []byte(classificationsSchema),
+		MaxTokens:    max(minResponseTokens, responseTokensPerFile*len(files)),
 		Temperature:  0.1,
 	}, nil
 }
 
-// 3) Verify (+ optional refine)
-func (t *Task) Verify(ctx context.Context, gathered pipeline.GatherOutput, response pipeline.LLMResponse) (bool, pipeline.VerifiedOutput, *pipeline.RefineRequest, error) {
-	var parsed []LLMResult
-	if err := json.Unmarshal([]byte(response.RawText), &parsed); err != nil {
-		return false, nil, &pipeline.RefineRequest{
-			UserPromptDelta: "The previous output was not valid JSON. Re-send strictly valid JSON only.",
-			Reason:          "invalid-json",
-		}, nil
+// Split chunks the inventory into batches of batching.size files so large folders fit the prompt.
+// Each *Batch carries its own verification state across refine attempts.
+func (t *Task) Split(ctx context.Context, gathered pipeline.GatherOutput) (pipeline.Batches, error) {
+	cfg, err := t.cfgProv.Load()
+	if err != nil {
+		return pipeline.Batches{}, err
+	}
+	size := cfg.Batching.Size
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	concurrency := cfg.Batching.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
 	}
 	files := gathered.([]FileMeta)
-	if len(parsed) != len(files) {
-		return false, nil, &pipeline.RefineRequest{
-			UserPromptDelta: fmt.Sprintf("You returned %d items for %d files. Return exactly one classification per file, ordered.", len(parsed), len(files)),
-			Reason:          "count-mismatch",
-		}, nil
+	var items []pipeline.GatherOutput
+	for start := 0; start < len(files); start += size {
+		items = append(items, newBatch(files[start:min(start+size, len(files))]))
+	}
+	return pipeline.Batches{Items: items, Concurrency: concurrency}, nil
+}
+
+// Merge concatenates the keyword-matched moves, the duplicate decisions and the per-batch plans;
+// files from failed batches simply stay where they are.
+func (t *Task) Merge(ctx context.Context, verified []pipeline.VerifiedOutput) (pipeline.VerifiedOutput, error) {
+	cfg, err := t.cfgProv.Load()
+	if err != nil {
+		return nil, err
 	}
+	plan := MovePlan{DryRun: cfg.Grant.Safety.DryRun, StagingDir: cfg.Grant.BaseDirectories.Staging}
+	plan.Actions = append(plan.Actions, t.preclassified...)
+	plan.Actions = append(plan.Actions, t.duplicates.actions...)
+	plan.Skipped = append(plan.Skipped, t.duplicates.skipped...)
+	plan.Duplicates = t.duplicates.groups
+	for _, batch := range verified {
+		plan.Actions = append(plan.Actions, batch.(MovePlan).Actions...)
+		plan.Skipped = append(plan.Skipped, batch.(MovePlan).Skipped...)
+		plan.Proposals = mergeProposals(plan.Proposals, batch.(MovePlan).Proposals...)
+	}
+	t.Plan = plan
+	return plan, nil
+}
 
+// 3) Verify (+ optional refine)
+// Items are checked one by one: valid classifications are kept in the batch, and the refine
+// request re-asks only about the files that failed, each with its reason. While some files are
+// still pending the partial plan is returned alongside the refine so the runner can apply the
+// accepted moves if attempts run out.
+func (t *Task) Verify(ctx context.Context, gathered pipeline.GatherOutput, response pipeline.LLMResponse) (bool, pipeline.VerifiedOutput, *pipeline.RefineRequest, error) {
+	batch := asBatch(gathered)
 	cfg, err := t.cfgProv.Load()
 	if err != nil {
 		return false, nil, nil, err
 	}
+	parsed, parseErr := parseClassifications(response.RawText)
+	if parseErr != nil {
+		return false, batch.partialPlan(cfg), &pipeline.RefineRequest{
+			UserPromptDelta: "The previous output was not valid JSON. Re-send strictly valid JSON only.",
+			Reason:          "invalid-json",
+		}, nil
+	}
 	minConfidence := cfg.Thresholds.MinConfidence
 	if minConfidence <= 0 {
-		minConfidence = 0.6
+		minConfidence = defaultMinConfidence
 	}
-	projectNamePattern := regexp.MustCompile(`^[\w\- ]{2,64}$`)
-
-	var actions []MoveAction
-	for idx, item := range parsed {
-		if item.TargetSubdir == "" {
-			item.TargetSubdir = "Unsorted_Inbox"
+	policy, err := lowConfidencePolicy(cfg)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	answers := batch.answers(parsed)
+	var (
+		failures     []fileRejection
+		stillPending []int
+	)
+	for _, index := range batch.pending {
+		file := batch.Files[index]
+		item, answered := answers[index]
+		if !answered {
+			failures = append(failures, fileRejection{file: file, id: index, code: "missing-classification", detail: "no classification was returned for this id"})
+			stillPending = append(stillPending, index)
+			continue
+		}
+		if rejection, rejected := rejectClassification(item, file, minConfidence, policy); rejected {
+			failures = append(failures, rejection)
+			stillPending = append(stillPending, index)
+			continue
 		}
-		if item.IsNewProject {
-			if !projectNamePattern.MatchString(item.ProposedProject) {
-				return false, nil, &pipeline.RefineRequest{
-					UserPromptDelta: "The proposed project name is invalid. Use 2–64 characters: letters, numbers, space, dash, underscore.",
-					Reason:          "bad-project-name",
-				}, nil
+		lowConfidence := item.Confidence < minConfidence && !item.IsNewProject
+		if lowConfidence && policy == lowConfidenceSkip {
+			batch.skipped[index] = &SkippedFile{
+				Path:       file.AbsolutePath,
+				Confidence: item.Confidence,
+				Reason:     fmt.Sprintf("low confidence %.2f < %.2f", item.Confidence, minConfidence),
 			}
+			continue
 		}
-		if item.Confidence < minConfidence && !item.IsNewProject {
-			return false, nil, &pipeline.RefineRequest{
-				UserPromptDelta: "For low-confidence items, either raise confidence with clearer signals or assign 'Unsorted_Inbox'. Confidence must be >= threshold unless is_new_project=true.",
-				Reason:          "low-confidence",
-			}, nil
+		reason := strings.Join(item.Signals, ",")
+		if lowConfidence && policy == lowConfidenceRouteToInbox {
+			item.TargetSubdir = inboxSubdir
+			reason = strings.TrimSuffix(fmt.Sprintf("low confidence %.2f, routed to inbox,%s", item.Confidence, reason), ",")
 		}
-		targetDir := safeSegment(item.TargetSubdir)
-		to := t.fs.FS.Join(cfg.Grant.BaseDirectories.Staging, targetDir, files[idx].BaseName+files[idx].Extension)
-		actions = append(actions, MoveAction{
-			FromPath:   files[idx].AbsolutePath,
-			ToPath:     to,
-			Confidence: item.Confidence,
-			Reason:     strings.Join(item.Signals, ","),
-		})
-	}
-	plan := MovePlan{Actions: actions, DryRun: cfg.Grant.Safety.DryRun}
-	t.Plan = plan
-	return true, plan, nil, nil
+		if item.TargetSubdir == "" {
+			item.TargetSubdir = inboxSubdir
+		}
+		if name := strings.TrimSpace(item.ProposedProject); item.IsNewProject && !knownProject(cfg, name) {
+			batch.proposals = mergeProposals(batch.proposals, ProjectProposal{
+				Name:       name,
+				Target:     safeSegment(name),
+				Keywords:   item.ProposedKeywords,
+				Confidence: item.Confidence,
+				FileCount:  1,
+				Files:      []string{file.BaseName + file.Extension},
+			})
+		}
+		batch.actions[index] = &MoveAction{
+			FromPath:          file.AbsolutePath,
+			ToPath:            t.stagedPath(cfg, item.TargetSubdir, file),
+			Confidence:        item.Confidence,
+			Reason:            reason,
+			LowConfidence:     lowConfidence,
+			NeedsConfirmation: lowConfidence && policy == lowConfidenceAsk,
+		}
+	}
+	accepted := len(batch.pending) - len(stillPending)
+	batch.pending = stillPending
+	if len(failures) == 0 {
+		return true, batch.plan(cfg), nil, nil
+	}
+	pipeline.LoggerFromContext(ctx).Info("classifications rejected",
+		zap.Int("accepted", accepted),
+		zap.Int("rejected", len(failures)),
+	)
+	return false, batch.partialPlan(cfg), perFileRefine(failures), nil
 }
 
 // 4) Apply
 func (t *Task) Apply(ctx context.Context, verified pipeline.VerifiedOutput) (pipeline.ApplyReport, error) {
 	plan := verified.(MovePlan)
-	return t.applyMovePlan(plan)
+	plan.DryRun = plan.DryRun || pipeline.DryRunFromContext(ctx)
+	return t.applyMovePlan(ctx, plan)
+}
+
+// Unsorted lists the gathered files the merged plan neither moves nor skips, i.e. those of
+// failed batches and those the model never classified.
+func (t *Task) Unsorted() []string {
+	handled := make(map[string]bool, len(t.Plan.Actions)+len(t.Plan.Skipped))
+	for _, action := range t.Plan.Actions {
+		handled[action.FromPath] = true
+	}
+	for _, skipped := range t.Plan.Skipped {
+		handled[skipped.Path] = true
+	}
+	var unsorted []string
+	for _, file := range t.Inventory {
+		if !handled[file.AbsolutePath] {
+			unsorted = append(unsorted, file.AbsolutePath)
+		}
+	}
+	return unsorted
 }
 
 // --- local helpers ---
 
+// parseClassifications decodes the schema envelope {"results":[...]}.
+func parseClassifications(raw string) ([]LLMResult, error) {
+	var envelope classifications
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Results, nil
+}
+
 func (t *Task) loadProjectListJSON() string {
 	cfg, _ := t.cfgProv.Load()
 	type Project struct {
@@ -204,13 +458,28 @@ func (t *Task) loadProjectListJSON() string {
 	return string(b)
 }
 
+func (t *Task) selected(infos []fsops.FileInfo) []fsops.FileInfo {
+	kept := infos[:0:0]
+	for _, info := range infos {
+		if t.only[t.fs.FS.Clean(info.AbsolutePath)] {
+			kept = append(kept, info)
+		}
+	}
+	return kept
+}
+
+// stagedPath is where file lands inside the staging directory for a target subdirectory.
+func (t *Task) stagedPath(cfg config.Sort, targetSubdir string, file FileMeta) string {
+	return t.fs.FS.Join(cfg.Grant.BaseDirectories.Staging, safeSegment(targetSubdir), file.BaseName+file.Extension)
+}
+
 func safeSegment(s string) string {
 	s = strings.TrimSpace(s)
 	re := regexp.MustCompile(`[^a-zA-Z0-9 _\-]`)
 	s = re.ReplaceAllString(s, "_")
 	s = strings.Trim(s, " _-")
 	if s == "" {
-		return "Unsorted_Inbox"
+		return inboxSubdir
 	}
 	return s
 }
@@ -3,8 +3,11 @@ package sort_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/temirov/llm-tasks/internal/pipeline"
@@ -50,7 +53,7 @@ thresholds:
 
 func marshalResults(t *testing.T, results []sorttask.LLMResult) string {
 	t.Helper()
-	b, err := json.Marshal(results)
+	b, err := json.Marshal(map[string][]sorttask.LLMResult{"results": results})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -77,9 +80,10 @@ func TestSort_VerifyAndApply_DryRun(t *testing.T) {
 		t.Fatalf("gather: %v", err)
 	}
 
+	// answers arrive out of order; the ids (inventory positions: image.png, report.csv) decide
 	results := []sorttask.LLMResult{
-		{ProjectName: "Data_CSV", TargetSubdir: "Data_CSV", Confidence: 0.90, Signals: []string{"csv ext"}},
-		{ProjectName: "", TargetSubdir: "Unsorted_Inbox", Confidence: 0.70, Signals: []string{"unknown"}},
+		{ID: 1, ProjectName: "Data_CSV", TargetSubdir: "Data_CSV", Confidence: 0.90, Signals: []string{"csv ext"}},
+		{ID: 0, ProjectName: "", TargetSubdir: "Unsorted_Inbox", Confidence: 0.70, Signals: []string{"unknown"}},
 	}
 
 	// Verify
@@ -99,13 +103,20 @@ func TestSort_VerifyAndApply_DryRun(t *testing.T) {
 	}
 
 	// Apply (dry run)
-	report, err := task.Apply(context.Background(), verified)
+	var report pipeline.ApplyReport
+	printed := captureStdout(t, func() {
+		report, err = task.Apply(context.Background(), verified)
+	})
 	if err != nil {
 		t.Fatalf("apply: %v", err)
 	}
 	if !report.DryRun {
 		t.Fatalf("expected dry-run")
 	}
+	expectedLine := fmt.Sprintf("would move %s -> %s (confidence 0.90)\n", csv, filepath.Join(staging, "Data_CSV", "report.csv"))
+	if !strings.Contains(printed, expectedLine) {
+		t.Fatalf("expected planned move %q on stdout, got %q", expectedLine, printed)
+	}
 	if _, err := os.Stat(img); err != nil {
 		t.Fatalf("expected image to still exist: %v", err)
 	}
@@ -114,7 +125,7 @@ func TestSort_VerifyAndApply_DryRun(t *testing.T) {
 	}
 }
 
-func TestSort_Verify_RejectsCountMismatch(t *testing.T) {
+func TestSort_Verify_RefinesMissingClassifications(t *testing.T) {
 	base := t.TempDir()
 	downloads := filepath.Join(base, "001")
 	staging := filepath.Join(base, "001", "_sorted")
@@ -131,9 +142,9 @@ func TestSort_Verify_RejectsCountMismatch(t *testing.T) {
 		t.Fatalf("gather: %v", err)
 	}
 
-	// LLM returns only 1 item for 2 files -> should request refine
+	// LLM returns only 1 item for 2 files -> should request refine for the other one
 	resp := marshalResults(t, []sorttask.LLMResult{
-		{ProjectName: "", TargetSubdir: "Unsorted_Inbox", Confidence: 1.0},
+		{ID: 0, ProjectName: "", TargetSubdir: "Unsorted_Inbox", Confidence: 1.0},
 	})
 	ok, _, refine, err := task.Verify(
 		context.Background(),
@@ -144,9 +155,180 @@ func TestSort_Verify_RejectsCountMismatch(t *testing.T) {
 		t.Fatalf("verify: %v", err)
 	}
 	if ok {
-		t.Fatalf("expected not accepted due to count mismatch")
+		t.Fatalf("expected not accepted due to a missing classification")
+	}
+	if refine == nil || refine.Reason != "missing-classification" || !strings.Contains(refine.UserPromptDelta, "id 1,") {
+		t.Fatalf("expected refine: missing-classification for id 1, got %+v", refine)
+	}
+}
+
+func TestSort_Prompt_DeclaresSchemaAndVerifyAcceptsEnvelope(t *testing.T) {
+	base := t.TempDir()
+	downloads := filepath.Join(base, "001")
+	staging := filepath.Join(base, "001", "_sorted")
+	_ = os.MkdirAll(downloads, 0o755)
+
+	_ = writeTempFile(t, downloads, "report.csv", "a,b\n")
+
+	cfgPath := makeTempConfig(t, downloads, staging, true)
+	t.Setenv("LLMTASKS_SORT_CONFIG", cfgPath)
+
+	task := sorttask.New().(*sorttask.Task)
+	gathered, err := task.Gather(context.Background())
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	req, err := task.Prompt(context.Background(), gathered)
+	if err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	if len(req.JSONSchema) == 0 {
+		t.Fatalf("expected prompt to declare a JSON schema")
+	}
+
+	envelope, err := json.Marshal(map[string][]sorttask.LLMResult{
+		"results": {{ProjectName: "Data_CSV", TargetSubdir: "Data_CSV", Confidence: 0.9, ProposedKeywords: []string{}, Signals: []string{"csv ext"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pipeline.ValidateJSONSchema(req.JSONSchema, string(envelope)); err != nil {
+		t.Fatalf("envelope should satisfy schema: %v", err)
+	}
+	ok, _, refine, err := task.Verify(context.Background(), gathered, pipeline.LLMResponse{RawText: string(envelope)})
+	if err != nil || !ok {
+		t.Fatalf("expected envelope to be accepted, ok=%v refine=%+v err=%v", ok, refine, err)
+	}
+}
+
+func TestSort_Apply_ContextDryRunOverridesRecipe(t *testing.T) {
+	base := t.TempDir()
+	downloads := filepath.Join(base, "001")
+	staging := filepath.Join(base, "001", "_sorted")
+	_ = os.MkdirAll(downloads, 0o755)
+
+	csv := writeTempFile(t, downloads, "report.csv", "a,b\n")
+
+	cfgPath := makeTempConfig(t, downloads, staging, false)
+	t.Setenv("LLMTASKS_SORT_CONFIG", cfgPath)
+
+	task := sorttask.New().(*sorttask.Task)
+	if _, err := task.Gather(context.Background()); err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	resp := marshalResults(t, []sorttask.LLMResult{
+		{ProjectName: "Data_CSV", TargetSubdir: "Data_CSV", Confidence: 0.9},
+	})
+	ok, verified, _, err := task.Verify(context.Background(), task.Inventory, pipeline.LLMResponse{RawText: resp})
+	if err != nil || !ok {
+		t.Fatalf("verify: ok=%v err=%v", ok, err)
+	}
+
+	report, err := task.Apply(pipeline.WithDryRun(context.Background(), true), verified)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !report.DryRun {
+		t.Fatalf("expected dry-run report")
+	}
+	if _, err := os.Stat(csv); err != nil {
+		t.Fatalf("expected csv to stay in place: %v", err)
+	}
+}
+
+func TestSort_SplitAndMergeBatches(t *testing.T) {
+	base := t.TempDir()
+	downloads := filepath.Join(base, "001")
+	staging := filepath.Join(base, "001", "_sorted")
+	_ = os.MkdirAll(downloads, 0o755)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		_ = writeTempFile(t, downloads, name, name)
+	}
+
+	cfgPath := makeTempConfig(t, downloads, staging, true)
+	cfg, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = append(cfg, []byte("batching:\n  size: 2\n  concurrency: 3\n")...)
+	if err := os.WriteFile(cfgPath, cfg, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LLMTASKS_SORT_CONFIG", cfgPath)
+
+	task := sorttask.New().(*sorttask.Task)
+	gathered, err := task.Gather(context.Background())
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	batches, err := task.Split(context.Background(), gathered)
+	if err != nil {
+		t.Fatalf("split: %v", err)
 	}
-	if refine == nil || refine.Reason != "count-mismatch" {
-		t.Fatalf("expected refine: count-mismatch, got %+v", refine)
+	if len(batches.Items) != 3 || batches.Concurrency != 3 {
+		t.Fatalf("expected 3 batches at concurrency 3, got %d at %d", len(batches.Items), batches.Concurrency)
+	}
+
+	var verified []pipeline.VerifiedOutput
+	for _, batch := range batches.Items[:2] { // the last batch "failed"
+		files := batch.(*sorttask.Batch).Files
+		results := make([]sorttask.LLMResult, len(files))
+		for i := range results {
+			results[i] = sorttask.LLMResult{ID: i, TargetSubdir: "Unsorted_Inbox", Confidence: 0.9}
+		}
+		ok, out, refine, err := task.Verify(context.Background(), batch, pipeline.LLMResponse{RawText: marshalResults(t, results)})
+		if err != nil || !ok {
+			t.Fatalf("verify batch: ok=%v refine=%+v err=%v", ok, refine, err)
+		}
+		verified = append(verified, out)
+	}
+	merged, err := task.Merge(context.Background(), verified)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	plan := merged.(sorttask.MovePlan)
+	if len(plan.Actions) != 4 || !plan.DryRun || plan.StagingDir != staging {
+		t.Fatalf("unexpected merged plan %+v", plan)
+	}
+	failed := batches.Items[2].(*sorttask.Batch).Files
+	if unsorted := task.Unsorted(); len(unsorted) != 1 || unsorted[0] != failed[0].AbsolutePath {
+		t.Fatalf("expected only %s from the failed batch to be unsorted, got %v", failed[0].AbsolutePath, unsorted)
+	}
+}
+
+func TestSort_NewForFiles_GathersOnlyListedFiles(t *testing.T) {
+	base := t.TempDir()
+	downloads := filepath.Join(base, "001")
+	staging := filepath.Join(base, "001", "_sorted")
+	_ = os.MkdirAll(downloads, 0o755)
+
+	_ = writeTempFile(t, downloads, "old.txt", "x")
+	arrived := writeTempFile(t, downloads, "new.csv", "a,b\n")
+
+	provider := sorttask.FileSortConfigProvider{DefaultPath: makeTempConfig(t, downloads, staging, true)}
+	task := sorttask.NewForFiles(sorttask.DefaultFS(), provider, []string{arrived})
+	if _, err := task.Gather(context.Background()); err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(task.Inventory) != 1 || task.Inventory[0].AbsolutePath != arrived {
+		t.Fatalf("expected only %s in the inventory, got %+v", arrived, task.Inventory)
+	}
+}
+
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	original := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = original }()
+	run()
+	_ = writer.Close()
+	printed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read stdout: %v", err)
 	}
+	return string(printed)
 }